@@ -0,0 +1,94 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/joho/godotenv"
+
+	"myProfessor/internal/config"
+	"myProfessor/internal/storage"
+)
+
+func main() {
+	_ = godotenv.Load()
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatalf("failed to load config: %v", err)
+	}
+
+	source := flag.String("source", filepath.Join(cfg.DataDir, "meta.json"), "meta.json file to migrate")
+	target := flag.String("target", cfg.SQLitePath, "SQLite database to create")
+	dryRun := flag.Bool("dry-run", false, "validate and report without writing the target")
+	strict := flag.Bool("strict", false, "abort when inconsistencies are found")
+	flag.Parse()
+
+	snapshot, err := storage.ReadMetaFile(*source)
+	if err != nil {
+		log.Fatalf("failed to read %s: %v", *source, err)
+	}
+
+	issues := storage.ValidateSnapshot(snapshot)
+	printReport(*source, snapshot, issues)
+
+	if *strict && len(issues) > 0 {
+		log.Fatalf("aborting: %d inconsistencies found", len(issues))
+	}
+	if *dryRun {
+		fmt.Println("dry run: nothing written")
+		return
+	}
+
+	if _, err := os.Stat(*target); err == nil {
+		log.Fatalf("target %s already exists, refusing to overwrite it", *target)
+	}
+
+	store, err := storage.NewSQLiteStore(*target)
+	if err != nil {
+		log.Fatalf("failed to open %s: %v", *target, err)
+	}
+	if err := store.Import(snapshot); err != nil {
+		store.Close()
+		os.Remove(*target)
+		log.Fatalf("migration failed, nothing written: %v", err)
+	}
+	if err := store.Close(); err != nil {
+		log.Fatalf("failed to close %s: %v", *target, err)
+	}
+
+	fmt.Printf("migrated %d folders and %d documents to %s\n", len(snapshot.Folders), len(snapshot.Documents), *target)
+	fmt.Printf("set STORE_BACKEND=%s and SQLITE_PATH=%s to use it\n", config.StoreBackendSQLite, *target)
+}
+
+func printReport(source string, snapshot storage.Snapshot, issues []storage.MigrationIssue) {
+	fmt.Printf("source: %s\n", source)
+	fmt.Printf("folders: %d\n", len(snapshot.Folders))
+	fmt.Printf("documents: %d\n", len(snapshot.Documents))
+
+	if len(issues) == 0 {
+		fmt.Println("inconsistencies: none")
+		return
+	}
+
+	counts := map[string]int{}
+	kinds := make([]string, 0)
+	for _, issue := range issues {
+		if counts[issue.Kind] == 0 {
+			kinds = append(kinds, issue.Kind)
+		}
+		counts[issue.Kind]++
+	}
+
+	fmt.Printf("inconsistencies: %d\n", len(issues))
+	for _, kind := range kinds {
+		fmt.Printf("  %s: %d\n", kind, counts[kind])
+	}
+	for _, issue := range issues {
+		fmt.Printf("  - %s\n", issue)
+	}
+	fmt.Println("folder membership is rebuilt from each document's folderId in the target")
+}
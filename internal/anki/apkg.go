@@ -0,0 +1,384 @@
+package anki
+
+import (
+	"archive/zip"
+	"crypto/sha1"
+	"database/sql"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	_ "modernc.org/sqlite"
+)
+
+const (
+	CollectionFile = "collection.anki2"
+	MediaFile      = "media"
+
+	schemaVersion = 11
+	fieldSep      = "\x1f"
+	defaultDeckID = 1
+	defaultConfID = 1
+)
+
+const collectionSchema = `
+CREATE TABLE col (
+	id integer primary key, crt integer not null, mod integer not null, scm integer not null,
+	ver integer not null, dty integer not null, usn integer not null, ls integer not null,
+	conf text not null, models text not null, decks text not null, dconf text not null, tags text not null
+);
+CREATE TABLE notes (
+	id integer primary key, guid text not null, mid integer not null, mod integer not null,
+	usn integer not null, tags text not null, flds text not null, sfld integer not null,
+	csum integer not null, flags integer not null, data text not null
+);
+CREATE TABLE cards (
+	id integer primary key, nid integer not null, did integer not null, ord integer not null,
+	mod integer not null, usn integer not null, type integer not null, queue integer not null,
+	due integer not null, ivl integer not null, factor integer not null, reps integer not null,
+	lapses integer not null, left integer not null, odue integer not null, odid integer not null,
+	flags integer not null, data text not null
+);
+CREATE TABLE revlog (
+	id integer primary key, cid integer not null, usn integer not null, ease integer not null,
+	ivl integer not null, lastIvl integer not null, factor integer not null, time integer not null,
+	type integer not null
+);
+CREATE TABLE graves (usn integer not null, oid integer not null, type integer not null);
+CREATE INDEX ix_notes_usn ON notes (usn);
+CREATE INDEX ix_cards_usn ON cards (usn);
+CREATE INDEX ix_revlog_usn ON revlog (usn);
+CREATE INDEX ix_cards_nid ON cards (nid);
+CREATE INDEX ix_cards_sched ON cards (did, queue, due);
+CREATE INDEX ix_revlog_cid ON revlog (cid);
+CREATE INDEX ix_notes_csum ON notes (csum);
+`
+
+const cardCSS = `.card {
+ font-family: arial;
+ font-size: 20px;
+ text-align: center;
+ color: black;
+ background-color: white;
+}`
+
+var htmlTag = regexp.MustCompile(`<[^>]*>`)
+
+type Note struct {
+	ID    string
+	Front string
+	Back  string
+	Tags  []string
+}
+
+type Deck struct {
+	ID    string
+	Name  string
+	Notes []Note
+}
+
+func WritePackage(w io.Writer, deck Deck, now time.Time) error {
+	if strings.TrimSpace(deck.Name) == "" {
+		return errors.New("deck name is required")
+	}
+
+	dir, err := os.MkdirTemp("", "apkg-*")
+	if err != nil {
+		return fmt.Errorf("create temp directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, CollectionFile)
+	if err := writeCollection(path, deck, now); err != nil {
+		return err
+	}
+
+	archive := zip.NewWriter(w)
+	if err := addFile(archive, CollectionFile, path); err != nil {
+		archive.Close()
+		return err
+	}
+	media, err := archive.Create(MediaFile)
+	if err != nil {
+		archive.Close()
+		return fmt.Errorf("add media manifest: %w", err)
+	}
+	if _, err := io.WriteString(media, "{}"); err != nil {
+		archive.Close()
+		return fmt.Errorf("write media manifest: %w", err)
+	}
+	if err := archive.Close(); err != nil {
+		return fmt.Errorf("close package: %w", err)
+	}
+	return nil
+}
+
+func Tag(text string) string {
+	fields := strings.FieldsFunc(text, func(r rune) bool {
+		return unicode.IsSpace(r) || r == '"'
+	})
+	return strings.Join(fields, "_")
+}
+
+func writeCollection(path string, deck Deck, now time.Time) error {
+	db, err := sql.Open("sqlite", "file:"+path)
+	if err != nil {
+		return fmt.Errorf("open collection: %w", err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin collection: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(collectionSchema); err != nil {
+		return fmt.Errorf("create collection schema: %w", err)
+	}
+
+	nowMillis := now.UnixMilli()
+	deckID := stableID(deck.ID + "/deck")
+	modelID := stableID(deck.ID + "/model")
+
+	conf, models, decks, dconf, err := collectionConfig(deck, deckID, modelID, now)
+	if err != nil {
+		return err
+	}
+	dayStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location()).Unix()
+	_, err = tx.Exec(`INSERT INTO col VALUES (1, ?, ?, ?, ?, 0, 0, 0, ?, ?, ?, ?, '{}')`,
+		dayStart, nowMillis, nowMillis, schemaVersion, conf, models, decks, dconf)
+	if err != nil {
+		return fmt.Errorf("insert collection: %w", err)
+	}
+
+	for i, note := range deck.Notes {
+		noteID := nowMillis + int64(i)
+		front := fieldHTML(note.Front)
+		sortField := stripHTML(front)
+
+		_, err := tx.Exec(`INSERT INTO notes VALUES (?, ?, ?, ?, -1, ?, ?, ?, ?, 0, '')`,
+			noteID, noteGUID(deck.ID, note.ID), modelID, now.Unix(), noteTags(note.Tags),
+			front+fieldSep+fieldHTML(note.Back), sortField, checksum(sortField))
+		if err != nil {
+			return fmt.Errorf("insert note: %w", err)
+		}
+
+		_, err = tx.Exec(`INSERT INTO cards VALUES (?, ?, ?, 0, ?, -1, 0, 0, ?, 0, 0, 0, 0, 0, 0, 0, 0, '')`,
+			noteID, noteID, deckID, now.Unix(), i+1)
+		if err != nil {
+			return fmt.Errorf("insert card: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit collection: %w", err)
+	}
+	return db.Close()
+}
+
+func collectionConfig(deck Deck, deckID, modelID int64, now time.Time) (conf, models, decks, dconf string, err error) {
+	mod := now.Unix()
+
+	confValue := map[string]any{
+		"nextPos":       len(deck.Notes) + 1,
+		"estTimes":      true,
+		"activeDecks":   []int64{deckID},
+		"sortType":      "noteFld",
+		"timeLim":       0,
+		"sortBackwards": false,
+		"addToCur":      true,
+		"curDeck":       deckID,
+		"newSpread":     0,
+		"dueCounts":     true,
+		"curModel":      strconv.FormatInt(modelID, 10),
+		"collapseTime":  1200,
+	}
+
+	modelsValue := map[string]any{
+		strconv.FormatInt(modelID, 10): map[string]any{
+			"id":    modelID,
+			"name":  "myProfessor - Recto/Verso",
+			"type":  0,
+			"mod":   mod,
+			"usn":   -1,
+			"sortf": 0,
+			"did":   deckID,
+			"tmpls": []map[string]any{{
+				"name":  "Carte 1",
+				"ord":   0,
+				"qfmt":  "{{Recto}}",
+				"afmt":  "{{FrontSide}}\n\n<hr id=answer>\n\n{{Verso}}",
+				"did":   nil,
+				"bqfmt": "",
+				"bafmt": "",
+			}},
+			"flds": []map[string]any{
+				modelField("Recto", 0),
+				modelField("Verso", 1),
+			},
+			"css":       cardCSS,
+			"latexPre":  "\\documentclass[12pt]{article}\n\\special{papersize=3in,5in}\n\\usepackage{amssymb,amsmath}\n\\pagestyle{empty}\n\\setlength{\\parindent}{0in}\n\\begin{document}\n",
+			"latexPost": "\\end{document}",
+			"tags":      []string{},
+			"vers":      []any{},
+			"req":       []any{[]any{0, "all", []int{0}}},
+		},
+	}
+
+	decksValue := map[string]any{
+		strconv.Itoa(defaultDeckID):   deckEntry(defaultDeckID, "Default", mod),
+		strconv.FormatInt(deckID, 10): deckEntry(deckID, deck.Name, mod),
+	}
+
+	dconfValue := map[string]any{
+		strconv.Itoa(defaultConfID): map[string]any{
+			"id":       defaultConfID,
+			"name":     "Default",
+			"mod":      0,
+			"usn":      0,
+			"maxTaken": 60,
+			"autoplay": true,
+			"timer":    0,
+			"replayq":  true,
+			"dyn":      false,
+			"new": map[string]any{
+				"delays":        []float64{1, 10},
+				"ints":          []int{1, 4, 7},
+				"initialFactor": 2500,
+				"separate":      true,
+				"order":         1,
+				"perDay":        20,
+				"bury":          true,
+			},
+			"rev": map[string]any{
+				"perDay":   200,
+				"ease4":    1.3,
+				"fuzz":     0.05,
+				"minSpace": 1,
+				"ivlFct":   1,
+				"maxIvl":   36500,
+				"bury":     true,
+			},
+			"lapse": map[string]any{
+				"delays":      []float64{10},
+				"mult":        0,
+				"minInt":      1,
+				"leechFails":  8,
+				"leechAction": 0,
+			},
+		},
+	}
+
+	values := []any{confValue, modelsValue, decksValue, dconfValue}
+	encoded := make([]string, len(values))
+	for i, value := range values {
+		data, err := json.Marshal(value)
+		if err != nil {
+			return "", "", "", "", fmt.Errorf("encode collection config: %w", err)
+		}
+		encoded[i] = string(data)
+	}
+	return encoded[0], encoded[1], encoded[2], encoded[3], nil
+}
+
+func modelField(name string, ord int) map[string]any {
+	return map[string]any{
+		"name":   name,
+		"ord":    ord,
+		"sticky": false,
+		"rtl":    false,
+		"font":   "Arial",
+		"size":   20,
+		"media":  []string{},
+	}
+}
+
+func deckEntry(id int64, name string, mod int64) map[string]any {
+	return map[string]any{
+		"id":               id,
+		"name":             name,
+		"mod":              mod,
+		"usn":              -1,
+		"desc":             "",
+		"dyn":              0,
+		"conf":             defaultConfID,
+		"collapsed":        false,
+		"newToday":         []int{0, 0},
+		"revToday":         []int{0, 0},
+		"lrnToday":         []int{0, 0},
+		"timeToday":        []int{0, 0},
+		"extendNew":        10,
+		"extendRev":        50,
+		"browserCollapsed": false,
+	}
+}
+
+func addFile(archive *zip.Writer, name, path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("open %s: %w", name, err)
+	}
+	defer src.Close()
+
+	dst, err := archive.Create(name)
+	if err != nil {
+		return fmt.Errorf("add %s: %w", name, err)
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		return fmt.Errorf("write %s: %w", name, err)
+	}
+	return nil
+}
+
+func fieldHTML(text string) string {
+	text = html.EscapeString(strings.TrimSpace(text))
+	return strings.ReplaceAll(text, "\n", "<br>")
+}
+
+func stripHTML(text string) string {
+	text = strings.ReplaceAll(text, "<br>", " ")
+	return html.UnescapeString(htmlTag.ReplaceAllString(text, ""))
+}
+
+func noteTags(tags []string) string {
+	cleaned := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		if tag = Tag(tag); tag != "" {
+			cleaned = append(cleaned, tag)
+		}
+	}
+	if len(cleaned) == 0 {
+		return ""
+	}
+	return " " + strings.Join(cleaned, " ") + " "
+}
+
+func checksum(text string) int64 {
+	sum := sha1.Sum([]byte(text))
+	value, _ := strconv.ParseInt(hex.EncodeToString(sum[:4]), 16, 64)
+	return value
+}
+
+func noteGUID(deckID, noteID string) string {
+	sum := sha1.Sum([]byte(deckID + "/" + noteID))
+	return base64.RawStdEncoding.EncodeToString(sum[:8])
+}
+
+func stableID(seed string) int64 {
+	sum := sha1.Sum([]byte(seed))
+	return int64(binary.BigEndian.Uint64(sum[:8])>>1)%1_000_000_000_000 + 1_000_000_000_000
+}
@@ -0,0 +1,131 @@
+package anki
+
+import (
+	"archive/zip"
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWritePackageProducesReadableCollection(t *testing.T) {
+	deck := Deck{
+		ID:   "folder-1",
+		Name: "Algèbre linéaire",
+		Notes: []Note{
+			{ID: "c1", Front: "Valeur propre ?", Back: "λ tel que Av = λv\n<pas du HTML>", Tags: []string{"Amphi 1 : matrices"}},
+			{ID: "c2", Front: "Trace", Back: "Somme de la diagonale", Tags: []string{"Amphi 2"}},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := WritePackage(&buf, deck, time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)); err != nil {
+		t.Fatalf("write package: %v", err)
+	}
+
+	db := openCollection(t, buf.Bytes())
+
+	var ver int
+	var decksJSON string
+	if err := db.QueryRow(`SELECT ver, decks FROM col`).Scan(&ver, &decksJSON); err != nil {
+		t.Fatalf("read col: %v", err)
+	}
+	if ver != schemaVersion {
+		t.Fatalf("expected schema version %d, got %d", schemaVersion, ver)
+	}
+	var decks map[string]struct {
+		Name string `json:"name"`
+	}
+	if err := json.Unmarshal([]byte(decksJSON), &decks); err != nil {
+		t.Fatalf("decode decks: %v", err)
+	}
+	deckID := ""
+	for id, d := range decks {
+		if d.Name == deck.Name {
+			deckID = id
+		}
+	}
+	if deckID == "" {
+		t.Fatalf("deck %q not found in %s", deck.Name, decksJSON)
+	}
+
+	rows, err := db.Query(`SELECT n.tags, n.flds, n.sfld, c.did FROM notes n JOIN cards c ON c.nid = n.id ORDER BY c.due`)
+	if err != nil {
+		t.Fatalf("query notes: %v", err)
+	}
+	defer rows.Close()
+
+	var tags, fields, sortFields, deckIDs []string
+	for rows.Next() {
+		var tag, flds, sfld, did string
+		if err := rows.Scan(&tag, &flds, &sfld, &did); err != nil {
+			t.Fatalf("scan note: %v", err)
+		}
+		tags = append(tags, tag)
+		fields = append(fields, flds)
+		sortFields = append(sortFields, sfld)
+		deckIDs = append(deckIDs, did)
+	}
+	if len(fields) != 2 {
+		t.Fatalf("expected 2 notes, got %d", len(fields))
+	}
+	if tags[0] != " Amphi_1_:_matrices " || deckIDs[0] != deckID || deckIDs[1] != deckID {
+		t.Fatalf("unexpected tags or decks: %q %v", tags, deckIDs)
+	}
+	if want := "Valeur propre ?" + fieldSep + "λ tel que Av = λv<br>&lt;pas du HTML&gt;"; fields[0] != want {
+		t.Fatalf("unexpected fields:\n got %q\nwant %q", fields[0], want)
+	}
+	if sortFields[1] != "Trace" {
+		t.Fatalf("unexpected sort field %q", sortFields[1])
+	}
+}
+
+func openCollection(t *testing.T, apkg []byte) *sql.DB {
+	t.Helper()
+
+	archive, err := zip.NewReader(bytes.NewReader(apkg), int64(len(apkg)))
+	if err != nil {
+		t.Fatalf("open package: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), CollectionFile)
+	found := map[string]bool{}
+	for _, file := range archive.File {
+		found[file.Name] = true
+		rc, err := file.Open()
+		if err != nil {
+			t.Fatalf("open %s: %v", file.Name, err)
+		}
+		data, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatalf("read %s: %v", file.Name, err)
+		}
+
+		switch file.Name {
+		case CollectionFile:
+			if err := os.WriteFile(path, data, 0o644); err != nil {
+				t.Fatalf("write collection: %v", err)
+			}
+		case MediaFile:
+			if strings.TrimSpace(string(data)) != "{}" {
+				t.Fatalf("unexpected media manifest %q", data)
+			}
+		}
+	}
+	if !found[CollectionFile] || !found[MediaFile] {
+		t.Fatalf("package is missing entries: %v", found)
+	}
+
+	db, err := sql.Open("sqlite", "file:"+path+"?mode=ro")
+	if err != nil {
+		t.Fatalf("open collection: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
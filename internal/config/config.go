@@ -5,19 +5,49 @@ import (
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"time"
 )
 
+const DefaultOpenAIBaseURL = "https://api.openai.com/v1"
+
+const (
+	TranscriptionProviderOpenAI     = "openai"
+	TranscriptionProviderWhisperCpp = "whispercpp"
+)
+
+const (
+	StoreBackendJSON   = "json"
+	StoreBackendSQLite = "sqlite"
+)
+
 type Config struct {
 	Port                  string
 	OpenAIAPIKey          string
+	OpenAIBaseURL         string
 	OpenAIModelTranscribe string
 	OpenAIModelSummary    string
+	OpenAIModelEmbedding  string
+	OpenAIVerboseJSON     bool
+	OpenAIWordTimestamps  bool
+	EmbeddingsEnabled     bool
+	TranscriptionProvider string
+	WhisperCppBinary      string
+	WhisperCppModel       string
+	WhisperCppLanguage    string
+	WhisperCppThreads     int
 	BaseURL               string
 	ShareSecret           string
 	ShareTTL              time.Duration
 	MaxUploadBytes        int64
 	DataDir               string
+	StoreBackend          string
+	SQLitePath            string
+	JobWorkers            int
+	JobRetention          time.Duration
+	RequeueInterrupted    bool
+	OrphanAfter           time.Duration
+	AdminToken            string
 }
 
 func LoadConfig() (Config, error) {
@@ -25,8 +55,49 @@ func LoadConfig() (Config, error) {
 
 	cfg.Port = envOrDefault("PORT", "8080")
 	cfg.OpenAIAPIKey = os.Getenv("OPENAI_API_KEY")
+	cfg.OpenAIBaseURL = strings.TrimRight(envOrDefault("OPENAI_BASE_URL", DefaultOpenAIBaseURL), "/")
 	cfg.OpenAIModelTranscribe = envOrDefault("OPENAI_MODEL_TRANSCRIBE", "whisper-1")
 	cfg.OpenAIModelSummary = envOrDefault("OPENAI_MODEL_SUMMARY", "gpt-4o-mini")
+	cfg.OpenAIModelEmbedding = envOrDefault("OPENAI_MODEL_EMBEDDING", "text-embedding-3-small")
+
+	verboseJSON, err := strconv.ParseBool(envOrDefault("OPENAI_VERBOSE_JSON", "true"))
+	if err != nil {
+		return Config{}, fmt.Errorf("parse OPENAI_VERBOSE_JSON: %w", err)
+	}
+	cfg.OpenAIVerboseJSON = verboseJSON
+
+	wordTimestamps, err := strconv.ParseBool(envOrDefault("OPENAI_WORD_TIMESTAMPS", "false"))
+	if err != nil {
+		return Config{}, fmt.Errorf("parse OPENAI_WORD_TIMESTAMPS: %w", err)
+	}
+	cfg.OpenAIWordTimestamps = wordTimestamps
+
+	embeddingsEnabled, err := strconv.ParseBool(envOrDefault("EMBEDDINGS_ENABLED", "false"))
+	if err != nil {
+		return Config{}, fmt.Errorf("parse EMBEDDINGS_ENABLED: %w", err)
+	}
+	cfg.EmbeddingsEnabled = embeddingsEnabled
+
+	cfg.TranscriptionProvider = strings.ToLower(envOrDefault("TRANSCRIPTION_PROVIDER", TranscriptionProviderOpenAI))
+	cfg.WhisperCppBinary = envOrDefault("WHISPER_CPP_BIN", "whisper-cli")
+	cfg.WhisperCppModel = os.Getenv("WHISPER_CPP_MODEL")
+	cfg.WhisperCppLanguage = envOrDefault("WHISPER_CPP_LANGUAGE", "fr")
+
+	whisperThreads, err := parseIntEnv("WHISPER_CPP_THREADS", 0)
+	if err != nil {
+		return Config{}, fmt.Errorf("parse WHISPER_CPP_THREADS: %w", err)
+	}
+	cfg.WhisperCppThreads = int(whisperThreads)
+
+	switch cfg.TranscriptionProvider {
+	case TranscriptionProviderOpenAI:
+	case TranscriptionProviderWhisperCpp:
+		if cfg.WhisperCppModel == "" {
+			return Config{}, fmt.Errorf("WHISPER_CPP_MODEL is required when TRANSCRIPTION_PROVIDER=%s", TranscriptionProviderWhisperCpp)
+		}
+	default:
+		return Config{}, fmt.Errorf("invalid TRANSCRIPTION_PROVIDER value %q (expected %s or %s)", cfg.TranscriptionProvider, TranscriptionProviderOpenAI, TranscriptionProviderWhisperCpp)
+	}
 
 	cfg.BaseURL = envOrDefault("BASE_URL", fmt.Sprintf("http://localhost:%s", cfg.Port))
 	cfg.ShareSecret = envOrDefault("SHARE_SECRET", "change-me")
@@ -44,12 +115,49 @@ func LoadConfig() (Config, error) {
 	}
 	cfg.MaxUploadBytes = maxUploadMB * 1024 * 1024
 
+	jobWorkers, err := parseIntEnv("JOB_WORKERS", 2)
+	if err != nil {
+		return Config{}, fmt.Errorf("parse JOB_WORKERS: %w", err)
+	}
+	cfg.JobWorkers = int(jobWorkers)
+
+	jobRetentionHours, err := parseIntEnv("JOB_RETENTION_HOURS", 168)
+	if err != nil {
+		return Config{}, fmt.Errorf("parse JOB_RETENTION_HOURS: %w", err)
+	}
+	cfg.JobRetention = time.Duration(jobRetentionHours) * time.Hour
+
+	switch mode := envOrDefault("RECOVER_INTERRUPTED", "requeue"); mode {
+	case "requeue":
+		cfg.RequeueInterrupted = true
+	case "fail":
+		cfg.RequeueInterrupted = false
+	default:
+		return Config{}, fmt.Errorf("invalid RECOVER_INTERRUPTED value %q (expected requeue or fail)", mode)
+	}
+
+	orphanAfterMinutes, err := parseIntEnv("ORPHAN_AFTER_MINUTES", 30)
+	if err != nil {
+		return Config{}, fmt.Errorf("parse ORPHAN_AFTER_MINUTES: %w", err)
+	}
+	cfg.OrphanAfter = time.Duration(orphanAfterMinutes) * time.Minute
+
+	cfg.AdminToken = os.Getenv("ADMIN_TOKEN")
+
 	absDataDir, err := filepath.Abs(cfg.DataDir)
 	if err != nil {
 		return Config{}, fmt.Errorf("resolve data dir: %w", err)
 	}
 	cfg.DataDir = absDataDir
 
+	cfg.StoreBackend = strings.ToLower(envOrDefault("STORE_BACKEND", StoreBackendJSON))
+	switch cfg.StoreBackend {
+	case StoreBackendJSON, StoreBackendSQLite:
+	default:
+		return Config{}, fmt.Errorf("invalid STORE_BACKEND value %q (expected %s or %s)", cfg.StoreBackend, StoreBackendJSON, StoreBackendSQLite)
+	}
+	cfg.SQLitePath = envOrDefault("SQLITE_PATH", filepath.Join(cfg.DataDir, "meta.db"))
+
 	return cfg, nil
 }
 
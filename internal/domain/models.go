@@ -1,28 +1,72 @@
 package domain
 
+import "slices"
+
 type Folder struct {
 	ID          string   `json:"id"`
 	Name        string   `json:"name"`
 	CreatedAt   int64    `json:"createdAt"`
 	UpdatedAt   int64    `json:"updatedAt"`
 	DocumentIDs []string `json:"documentIds"`
+	PDFTemplate string   `json:"pdfTemplate,omitempty"`
 }
 
 type Document struct {
-	ID                string `json:"id"`
-	FolderID          string `json:"folderId"`
-	Title             string `json:"title"`
-	Transcription     string `json:"transcription"`
-	Summary           string `json:"summary"`
-	Course            string `json:"course"`
-	AudioPath         string `json:"audioPath"`
-	OriginalAudioPath string `json:"originalAudioPath,omitempty"`
-	ProcessingStatus  string `json:"processingStatus"`
-	ProcessingError   string `json:"processingError,omitempty"`
-	PDFPath           string `json:"pdfPath,omitempty"`
-	SourceType        string `json:"sourceType"`
-	CreatedAt         int64  `json:"createdAt"`
-	UpdatedAt         int64  `json:"updatedAt"`
+	ID                string    `json:"id"`
+	FolderID          string    `json:"folderId"`
+	Title             string    `json:"title"`
+	Transcription     string    `json:"transcription"`
+	Segments          []Segment `json:"segments,omitempty"`
+	Summary           string    `json:"summary"`
+	Course            string    `json:"course"`
+	AudioPath         string    `json:"audioPath"`
+	OriginalAudioPath string    `json:"originalAudioPath,omitempty"`
+	ProcessingStatus  string    `json:"processingStatus"`
+	ProcessingError   string    `json:"processingError,omitempty"`
+	ChunksTotal       int       `json:"chunksTotal,omitempty"`
+	ChunksDone        int       `json:"chunksDone,omitempty"`
+	Pipeline          *Pipeline `json:"pipeline,omitempty"`
+	PDFPath           string    `json:"pdfPath,omitempty"`
+	SourceType        string    `json:"sourceType"`
+	CreatedAt         int64     `json:"createdAt"`
+	UpdatedAt         int64     `json:"updatedAt"`
+}
+
+func (d Document) Clone() Document {
+	if d.Segments != nil {
+		d.Segments = slices.Clone(d.Segments)
+		for i := range d.Segments {
+			d.Segments[i].Words = slices.Clone(d.Segments[i].Words)
+		}
+	}
+	if d.Pipeline != nil {
+		pipeline := *d.Pipeline
+		pipeline.Steps = slices.Clone(pipeline.Steps)
+		pipeline.Completed = slices.Clone(pipeline.Completed)
+		d.Pipeline = &pipeline
+	}
+	return d
+}
+
+type Pipeline struct {
+	Steps      []string `json:"steps"`
+	Completed  []string `json:"completed"`
+	Current    string   `json:"current,omitempty"`
+	FailedStep string   `json:"failedStep,omitempty"`
+	Status     string   `json:"status"`
+}
+
+type Segment struct {
+	Start float64 `json:"start"`
+	End   float64 `json:"end"`
+	Text  string  `json:"text"`
+	Words []Word  `json:"words,omitempty"`
+}
+
+type Word struct {
+	Start float64 `json:"start"`
+	End   float64 `json:"end"`
+	Text  string  `json:"text"`
 }
 
 const (
@@ -31,3 +75,125 @@ const (
 	ProcessingStatusCompleted  = "completed"
 	ProcessingStatusFailed     = "failed"
 )
+
+type Job struct {
+	ID         string `json:"id"`
+	Type       string `json:"type"`
+	DocumentID string `json:"documentId"`
+	Status     string `json:"status"`
+	Error      string `json:"error,omitempty"`
+	CreatedAt  int64  `json:"createdAt"`
+	UpdatedAt  int64  `json:"updatedAt"`
+}
+
+const (
+	JobTypeTranscribe = "transcribe"
+	JobTypePipeline   = "pipeline"
+)
+
+const (
+	PipelineStepTranscribe = "transcribe"
+	PipelineStepSummarize  = "summarize"
+	PipelineStepCourse     = "course"
+	PipelineStepPDF        = "pdf"
+)
+
+var PipelineSteps = []string{
+	PipelineStepTranscribe,
+	PipelineStepSummarize,
+	PipelineStepCourse,
+	PipelineStepPDF,
+}
+
+type Conversation struct {
+	Scope     string        `json:"scope"`
+	TargetID  string        `json:"targetId"`
+	Messages  []ChatMessage `json:"messages"`
+	UpdatedAt int64         `json:"updatedAt"`
+}
+
+type ChatMessage struct {
+	Role      string     `json:"role"`
+	Content   string     `json:"content"`
+	Citations []Citation `json:"citations,omitempty"`
+	CreatedAt int64      `json:"createdAt"`
+}
+
+type Citation struct {
+	Index      int      `json:"index"`
+	DocumentID string   `json:"documentId"`
+	Title      string   `json:"title,omitempty"`
+	Start      *float64 `json:"start,omitempty"`
+	End        *float64 `json:"end,omitempty"`
+	Excerpt    string   `json:"excerpt"`
+}
+
+const (
+	ConversationScopeDocument = "document"
+	ConversationScopeFolder   = "folder"
+
+	ChatRoleUser      = "user"
+	ChatRoleAssistant = "assistant"
+)
+
+type Quiz struct {
+	ID         string         `json:"id"`
+	DocumentID string         `json:"documentId"`
+	Title      string         `json:"title"`
+	Questions  []QuizQuestion `json:"questions"`
+	CreatedAt  int64          `json:"createdAt"`
+}
+
+type QuizQuestion struct {
+	ID          string   `json:"id"`
+	Type        string   `json:"type"`
+	Prompt      string   `json:"prompt"`
+	Choices     []string `json:"choices,omitempty"`
+	AnswerIndex *int     `json:"answerIndex,omitempty"`
+	Answer      string   `json:"answer,omitempty"`
+	Explanation string   `json:"explanation,omitempty"`
+}
+
+type QuizAttempt struct {
+	ID         string       `json:"id"`
+	QuizID     string       `json:"quizId"`
+	DocumentID string       `json:"documentId"`
+	Answers    []QuizAnswer `json:"answers"`
+	Score      float64      `json:"score"`
+	MaxScore   float64      `json:"maxScore"`
+	CreatedAt  int64        `json:"createdAt"`
+}
+
+type QuizAnswer struct {
+	QuestionID string  `json:"questionId"`
+	Choice     *int    `json:"choice,omitempty"`
+	Text       string  `json:"text,omitempty"`
+	Score      float64 `json:"score"`
+	Correct    bool    `json:"correct"`
+	Feedback   string  `json:"feedback,omitempty"`
+}
+
+const (
+	QuizQuestionMultipleChoice = "mcq"
+	QuizQuestionShortAnswer    = "short"
+)
+
+type Flashcard struct {
+	ID             string  `json:"id"`
+	DocumentID     string  `json:"documentId"`
+	Source         string  `json:"source"`
+	Front          string  `json:"front"`
+	Back           string  `json:"back"`
+	EaseFactor     float64 `json:"easeFactor"`
+	Interval       int     `json:"interval"`
+	Repetitions    int     `json:"repetitions"`
+	DueAt          int64   `json:"dueAt"`
+	LastReviewedAt int64   `json:"lastReviewedAt,omitempty"`
+	LastGrade      *int    `json:"lastGrade,omitempty"`
+	CreatedAt      int64   `json:"createdAt"`
+}
+
+const (
+	FlashcardSourceCourse  = "course"
+	FlashcardSourceSummary = "summary"
+)
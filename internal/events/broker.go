@@ -0,0 +1,72 @@
+package events
+
+import (
+	"sync"
+
+	"myProfessor/internal/domain"
+)
+
+const subscriberBuffer = 16
+
+type DocumentEvent struct {
+	Document domain.Document
+	Deleted  bool
+}
+
+type Broker struct {
+	mu   sync.Mutex
+	subs map[string]map[chan DocumentEvent]struct{}
+}
+
+func NewBroker() *Broker {
+	return &Broker{subs: map[string]map[chan DocumentEvent]struct{}{}}
+}
+
+func (b *Broker) Subscribe(documentID string) (<-chan DocumentEvent, func()) {
+	ch := make(chan DocumentEvent, subscriberBuffer)
+
+	b.mu.Lock()
+	if b.subs[documentID] == nil {
+		b.subs[documentID] = map[chan DocumentEvent]struct{}{}
+	}
+	b.subs[documentID][ch] = struct{}{}
+	b.mu.Unlock()
+
+	var once sync.Once
+	cancel := func() {
+		once.Do(func() {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+
+			delete(b.subs[documentID], ch)
+			if len(b.subs[documentID]) == 0 {
+				delete(b.subs, documentID)
+			}
+			close(ch)
+		})
+	}
+
+	return ch, cancel
+}
+
+func (b *Broker) Publish(doc domain.Document, deleted bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	event := DocumentEvent{Document: doc, Deleted: deleted}
+	for ch := range b.subs[doc.ID] {
+		select {
+		case ch <- event:
+		default:
+			// Slow subscriber: drop the oldest snapshot so the latest state still gets through.
+			select {
+			case <-ch:
+			default:
+			}
+			select {
+			case ch <- event:
+			default:
+			}
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package http
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"myProfessor/internal/anki"
+)
+
+func (a *API) handleExportAnki(c *gin.Context) {
+	folder, err := a.store.GetFolder(c.Param("id"))
+	if err != nil {
+		respondMessage(c, http.StatusNotFound, "folder not found")
+		return
+	}
+
+	generateMissing := c.Query("generate") == "missing"
+	deck := anki.Deck{ID: folder.ID, Name: folder.Name}
+	for _, doc := range a.store.ListDocumentsByFolder(folder.ID) {
+		cards := a.flashcards.ListByDocument(doc.ID)
+		if len(cards) == 0 && generateMissing {
+			source, content, err := flashcardSource(doc, "")
+			if err == nil {
+				cards, err = a.generateFlashcards(c.Request.Context(), doc, source, content, defaultFlashcards, false)
+				if errors.Is(err, errInvalidFlashcards) {
+					respondMessage(c, http.StatusBadGateway, err.Error())
+					return
+				}
+				if err != nil {
+					respondError(c, http.StatusInternalServerError, err)
+					return
+				}
+			}
+		}
+
+		title := strings.TrimSpace(doc.Title)
+		if title == "" {
+			title = doc.ID
+		}
+		for _, card := range cards {
+			deck.Notes = append(deck.Notes, anki.Note{ID: card.ID, Front: card.Front, Back: card.Back, Tags: []string{title}})
+		}
+	}
+
+	if len(deck.Notes) == 0 {
+		respondMessage(c, http.StatusConflict, "folder has no flashcards")
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := anki.WritePackage(&buf, deck, time.Now()); err != nil {
+		respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	filename := strings.TrimSpace(folder.Name)
+	if filename == "" {
+		filename = folder.ID
+	}
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename+".apkg"))
+	c.Data(http.StatusOK, "application/octet-stream", buf.Bytes())
+}
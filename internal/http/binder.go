@@ -0,0 +1,133 @@
+package http
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"myProfessor/internal/domain"
+	"myProfessor/internal/services"
+)
+
+func (a *API) handleGenerateFolderPDF(c *gin.Context) {
+	folder, err := a.store.GetFolder(c.Param("id"))
+	if err != nil {
+		respondMessage(c, http.StatusNotFound, "folder not found")
+		return
+	}
+
+	var payload struct {
+		Order    []string `json:"order"`
+		Sections []string `json:"sections"`
+		Template string   `json:"template"`
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&payload); err != nil {
+			respondMessage(c, http.StatusBadRequest, "invalid payload")
+			return
+		}
+	}
+
+	sections, err := services.ParsePDFSections(payload.Sections)
+	if err != nil {
+		respondMessage(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	template := strings.TrimSpace(payload.Template)
+	if template == "" {
+		template = a.folderPDFTemplate(folder)
+	} else if _, err := a.pdf.Template(template); err != nil {
+		respondMessage(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	docs, err := a.binderDocuments(folder, payload.Order)
+	if err != nil {
+		respondMessage(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	if len(docs) == 0 {
+		respondMessage(c, http.StatusConflict, "folder has no documents")
+		return
+	}
+
+	pdfPath := a.files.FolderPDFPath(folder.ID)
+	if err := a.pdf.GenerateFolderPDF(folder, docs, pdfPath, services.PDFOptions{Sections: sections, Template: template}); err != nil {
+		respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	ids := make([]string, len(docs))
+	for i, doc := range docs {
+		ids[i] = doc.ID
+	}
+	c.JSON(http.StatusOK, gin.H{"pdfPath": pdfPath, "documentIds": ids})
+}
+
+func (a *API) binderDocuments(folder domain.Folder, order []string) ([]domain.Document, error) {
+	docs := make([]domain.Document, 0, len(folder.DocumentIDs))
+	for _, id := range folder.DocumentIDs {
+		doc, err := a.store.GetDocument(id)
+		if err != nil {
+			continue
+		}
+		docs = append(docs, doc)
+	}
+	slices.SortStableFunc(docs, func(x, y domain.Document) int {
+		return cmp.Compare(x.CreatedAt, y.CreatedAt)
+	})
+
+	if len(order) == 0 {
+		return docs, nil
+	}
+
+	ordered := make([]domain.Document, 0, len(docs))
+	for _, id := range order {
+		index := slices.IndexFunc(docs, func(doc domain.Document) bool { return doc.ID == id })
+		if index < 0 {
+			if slices.ContainsFunc(ordered, func(doc domain.Document) bool { return doc.ID == id }) {
+				return nil, fmt.Errorf("document %s is listed twice in order", id)
+			}
+			return nil, fmt.Errorf("document %s is not in folder %s", id, folder.ID)
+		}
+		ordered = append(ordered, docs[index])
+		docs = slices.Delete(docs, index, index+1)
+	}
+	return append(ordered, docs...), nil
+}
+
+func (a *API) handleShareFolder(c *gin.Context) {
+	folderID := c.Param("id")
+	if _, err := a.store.GetFolder(folderID); err != nil {
+		respondMessage(c, http.StatusNotFound, "folder not found")
+		return
+	}
+
+	if _, err := os.Stat(a.files.FolderPDFPath(folderID)); errors.Is(err, os.ErrNotExist) {
+		respondMessage(c, http.StatusBadRequest, "no pdf available for this folder")
+		return
+	}
+
+	url, expiresAt, err := a.share.GenerateFolder(folderID)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"url": url, "expiresAt": expiresAt.UTC()})
+}
+
+func (a *API) handleServeFolderPDF(c *gin.Context) {
+	if !a.checkSignedURL(c) {
+		return
+	}
+
+	servePDFFile(c, a.files.FolderPDFPath(c.Param("id")))
+}
@@ -0,0 +1,268 @@
+package http
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+
+	"myProfessor/internal/domain"
+	"myProfessor/internal/search"
+	"myProfessor/internal/semantic"
+	"myProfessor/internal/services"
+)
+
+const (
+	chatPassages       = 6
+	citationExcerptLen = 240
+)
+
+var citationPattern = regexp.MustCompile(`\[(\d+)\]`)
+
+func (a *API) handleDocumentChat(c *gin.Context) {
+	doc, ok := a.documentOr404(c)
+	if !ok {
+		return
+	}
+	if strings.TrimSpace(doc.Transcription) == "" {
+		respondMessage(c, http.StatusBadRequest, errNoTranscription.Error())
+		return
+	}
+
+	a.answerQuestion(c, domain.ConversationScopeDocument, doc.ID, []domain.Document{doc})
+}
+
+func (a *API) handleFolderChat(c *gin.Context) {
+	folder, err := a.store.GetFolder(c.Param("id"))
+	if err != nil {
+		respondMessage(c, http.StatusNotFound, "folder not found")
+		return
+	}
+
+	docs := make([]domain.Document, 0)
+	for _, doc := range a.store.ListDocumentsByFolder(folder.ID) {
+		if strings.TrimSpace(doc.Transcription) != "" {
+			docs = append(docs, doc)
+		}
+	}
+	if len(docs) == 0 {
+		respondMessage(c, http.StatusBadRequest, "folder has no transcribed documents")
+		return
+	}
+
+	a.answerQuestion(c, domain.ConversationScopeFolder, folder.ID, docs)
+}
+
+func (a *API) handleGetConversation(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		targetID, ok := a.conversationTargetOr404(c, scope)
+		if !ok {
+			return
+		}
+
+		conversation, err := a.conversations.Get(scope, targetID)
+		if err != nil {
+			respondError(c, http.StatusInternalServerError, err)
+			return
+		}
+		c.JSON(http.StatusOK, conversation)
+	}
+}
+
+func (a *API) handleDeleteConversation(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		targetID, ok := a.conversationTargetOr404(c, scope)
+		if !ok {
+			return
+		}
+
+		if err := a.conversations.Delete(scope, targetID); err != nil {
+			respondError(c, http.StatusInternalServerError, err)
+			return
+		}
+		c.Status(http.StatusNoContent)
+	}
+}
+
+func (a *API) conversationTargetOr404(c *gin.Context, scope string) (string, bool) {
+	if scope == domain.ConversationScopeFolder {
+		folder, err := a.store.GetFolder(c.Param("id"))
+		if err != nil {
+			respondMessage(c, http.StatusNotFound, "folder not found")
+			return "", false
+		}
+		return folder.ID, true
+	}
+
+	doc, ok := a.documentOr404(c)
+	return doc.ID, ok
+}
+
+func (a *API) answerQuestion(c *gin.Context, scope, targetID string, docs []domain.Document) {
+	var payload struct {
+		Question string `json:"question"`
+	}
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		respondMessage(c, http.StatusBadRequest, "invalid payload")
+		return
+	}
+	question := strings.TrimSpace(payload.Question)
+	if question == "" {
+		respondMessage(c, http.StatusBadRequest, "question is required")
+		return
+	}
+
+	conversation, err := a.conversations.Get(scope, targetID)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx := c.Request.Context()
+	passages := a.retrievePassages(ctx, question, docs)
+
+	answer, err := a.generator.AnswerQuestion(ctx, passages, conversation.Messages, question)
+	if err != nil {
+		log.Printf("chat answer failed: %v", err)
+		respondMessage(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	citations := citationsFor(answer, passages)
+	_, err = a.conversations.Append(scope, targetID,
+		domain.ChatMessage{Role: domain.ChatRoleUser, Content: question},
+		domain.ChatMessage{Role: domain.ChatRoleAssistant, Content: answer, Citations: citations},
+	)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"answer": answer, "citations": citations})
+}
+
+func (a *API) retrievePassages(ctx context.Context, question string, docs []domain.Document) []services.Passage {
+	byID := make(map[string]domain.Document, len(docs))
+	for _, doc := range docs {
+		byID[doc.ID] = doc
+	}
+
+	if a.semantic != nil {
+		allow := func(id string) bool {
+			_, ok := byID[id]
+			return ok
+		}
+		matches, err := a.semantic.Search(ctx, question, allow, chatPassages)
+		if err != nil {
+			log.Printf("semantic retrieval failed, falling back to keywords: %v", err)
+		}
+		if err == nil && len(matches) > 0 {
+			passages := make([]services.Passage, 0, len(matches))
+			for _, match := range matches {
+				passages = append(passages, passageFromChunk(byID[match.DocumentID], match.Chunk))
+			}
+			return passages
+		}
+	}
+
+	return keywordPassages(question, docs)
+}
+
+func keywordPassages(question string, docs []domain.Document) []services.Passage {
+	wanted := map[string]bool{}
+	for _, term := range search.Terms(question) {
+		wanted[term] = true
+	}
+
+	type candidate struct {
+		passage services.Passage
+		score   int
+	}
+
+	candidates := make([]candidate, 0)
+	for _, doc := range docs {
+		for _, chunk := range semantic.ChunkDocument(doc) {
+			score := 0
+			for _, term := range search.Terms(chunk.Text) {
+				if wanted[term] {
+					score++
+				}
+			}
+			if score == 0 {
+				continue
+			}
+			candidates = append(candidates, candidate{passage: passageFromChunk(doc, chunk), score: score})
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].score > candidates[j].score
+	})
+	if len(candidates) > chatPassages {
+		candidates = candidates[:chatPassages]
+	}
+
+	passages := make([]services.Passage, 0, len(candidates))
+	for _, candidate := range candidates {
+		passages = append(passages, candidate.passage)
+	}
+	return passages
+}
+
+func passageFromChunk(doc domain.Document, chunk semantic.Chunk) services.Passage {
+	return services.Passage{
+		DocumentID: doc.ID,
+		Title:      doc.Title,
+		Start:      chunk.Start,
+		End:        chunk.End,
+		Timed:      chunk.End > 0,
+		Text:       chunk.Text,
+	}
+}
+
+func citationsFor(answer string, passages []services.Passage) []domain.Citation {
+	citations := make([]domain.Citation, 0)
+	seen := map[int]bool{}
+
+	for _, match := range citationPattern.FindAllStringSubmatch(answer, -1) {
+		n, err := strconv.Atoi(match[1])
+		if err != nil || n < 1 || n > len(passages) || seen[n] {
+			continue
+		}
+		seen[n] = true
+
+		passage := passages[n-1]
+		citation := domain.Citation{
+			Index:      n,
+			DocumentID: passage.DocumentID,
+			Title:      passage.Title,
+			Excerpt:    excerpt(passage.Text, citationExcerptLen),
+		}
+		if passage.Timed {
+			start, end := passage.Start, passage.End
+			citation.Start = &start
+			citation.End = &end
+		}
+		citations = append(citations, citation)
+	}
+
+	return citations
+}
+
+func excerpt(text string, maxRunes int) string {
+	if utf8.RuneCountInString(text) <= maxRunes {
+		return text
+	}
+	runes := []rune(text)
+	cut := string(runes[:maxRunes])
+	if space := strings.LastIndex(cut, " "); space > 0 {
+		cut = cut[:space]
+	}
+	return cut + "…"
+}
@@ -0,0 +1,64 @@
+package http
+
+import (
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (a *API) handleStreamCourse(c *gin.Context) {
+	doc, ok := a.documentOr404(c)
+	if !ok {
+		return
+	}
+
+	if strings.TrimSpace(doc.Transcription) == "" {
+		respondMessage(c, http.StatusBadRequest, "document has no transcription")
+		return
+	}
+
+	var payload struct {
+		Instructions string `json:"instructions"`
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&payload); err != nil {
+			respondMessage(c, http.StatusBadRequest, "invalid payload")
+			return
+		}
+	}
+
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+
+	ctx := c.Request.Context()
+	course, err := a.generator.StreamCourse(ctx, doc.Transcription, payload.Instructions, func(delta string) error {
+		c.SSEvent("delta", gin.H{"content": delta})
+		c.Writer.Flush()
+		return ctx.Err()
+	})
+	if err != nil {
+		if ctx.Err() != nil {
+			log.Printf("course stream for document %s cancelled by client", doc.ID)
+			return
+		}
+		log.Printf("course stream failed: %v", err)
+		c.SSEvent("error", gin.H{"error": err.Error()})
+		return
+	}
+
+	latest, err := a.store.GetDocument(doc.ID)
+	if err != nil {
+		c.SSEvent("error", gin.H{"error": err.Error()})
+		return
+	}
+	latest.Course = course
+	if latest, err = a.store.UpdateDocument(latest); err != nil {
+		c.SSEvent("error", gin.H{"error": err.Error()})
+		return
+	}
+
+	c.SSEvent("done", gin.H{"course": latest.Course})
+}
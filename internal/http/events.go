@@ -0,0 +1,111 @@
+package http
+
+import (
+	"io"
+	"slices"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"myProfessor/internal/domain"
+)
+
+const eventsHeartbeat = 15 * time.Second
+
+type documentEvent struct {
+	name string
+	data any
+}
+
+func (a *API) handleDocumentEvents(c *gin.Context) {
+	doc, ok := a.documentOr404(c)
+	if !ok {
+		return
+	}
+
+	updates, cancel := a.events.Subscribe(doc.ID)
+	defer cancel()
+
+	if latest, err := a.store.GetDocument(doc.ID); err == nil {
+		doc = latest
+	}
+
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+
+	c.SSEvent("snapshot", doc)
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(eventsHeartbeat)
+	defer heartbeat.Stop()
+
+	prev := doc
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-c.Request.Context().Done():
+			return false
+		case <-heartbeat.C:
+			_, err := io.WriteString(w, ": keepalive\n\n")
+			return err == nil
+		case event, ok := <-updates:
+			if !ok {
+				return false
+			}
+			if event.Deleted {
+				c.SSEvent("deleted", gin.H{"id": doc.ID})
+				return false
+			}
+			for _, e := range documentEvents(prev, event.Document) {
+				c.SSEvent(e.name, e.data)
+			}
+			prev = event.Document
+			return true
+		}
+	})
+}
+
+func documentEvents(prev, next domain.Document) []documentEvent {
+	out := make([]documentEvent, 0, 3)
+
+	if prev.ProcessingStatus != next.ProcessingStatus || prev.ProcessingError != next.ProcessingError || pipelineChanged(prev.Pipeline, next.Pipeline) {
+		out = append(out, documentEvent{name: "status", data: gin.H{
+			"id":               next.ID,
+			"processingStatus": next.ProcessingStatus,
+			"processingError":  next.ProcessingError,
+			"pipeline":         next.Pipeline,
+		}})
+	}
+
+	if prev.ChunksDone != next.ChunksDone || prev.ChunksTotal != next.ChunksTotal {
+		out = append(out, documentEvent{name: "progress", data: gin.H{
+			"id":          next.ID,
+			"chunksDone":  next.ChunksDone,
+			"chunksTotal": next.ChunksTotal,
+		}})
+	}
+
+	contentChanged := prev.Transcription != next.Transcription ||
+		prev.Summary != next.Summary ||
+		prev.Course != next.Course ||
+		prev.PDFPath != next.PDFPath
+	if contentChanged || (prev.ProcessingStatus != next.ProcessingStatus && isFinalStatus(next.ProcessingStatus)) {
+		out = append(out, documentEvent{name: "document", data: next})
+	}
+
+	return out
+}
+
+func pipelineChanged(prev, next *domain.Pipeline) bool {
+	if prev == nil || next == nil {
+		return prev != next
+	}
+	return prev.Status != next.Status ||
+		prev.Current != next.Current ||
+		prev.FailedStep != next.FailedStep ||
+		!slices.Equal(prev.Completed, next.Completed)
+}
+
+func isFinalStatus(status string) bool {
+	return status == domain.ProcessingStatusCompleted || status == domain.ProcessingStatusFailed
+}
@@ -0,0 +1,173 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"myProfessor/internal/domain"
+	"myProfessor/internal/review"
+	"myProfessor/internal/services"
+	"myProfessor/internal/storage"
+)
+
+const (
+	defaultFlashcards    = 15
+	maxFlashcards        = 50
+	defaultDueReviewSize = 50
+)
+
+var errInvalidFlashcards = errors.New("invalid flashcards returned by model")
+
+func (a *API) handleGenerateFlashcards(c *gin.Context) {
+	doc, ok := a.documentOr404(c)
+	if !ok {
+		return
+	}
+
+	var payload struct {
+		Source  string `json:"source"`
+		Count   int    `json:"count"`
+		Replace bool   `json:"replace"`
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&payload); err != nil {
+			respondMessage(c, http.StatusBadRequest, "invalid payload")
+			return
+		}
+	}
+	if payload.Count == 0 {
+		payload.Count = defaultFlashcards
+	}
+	if payload.Count < 1 || payload.Count > maxFlashcards {
+		respondMessage(c, http.StatusBadRequest, "count must be between 1 and 50")
+		return
+	}
+
+	source, content, err := flashcardSource(doc, payload.Source)
+	if err != nil {
+		respondMessage(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	cards, err := a.generateFlashcards(c.Request.Context(), doc, source, content, payload.Count, payload.Replace)
+	if errors.Is(err, errInvalidFlashcards) {
+		respondMessage(c, http.StatusBadGateway, err.Error())
+		return
+	}
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusCreated, cards)
+}
+
+func (a *API) handleListFlashcards(c *gin.Context) {
+	doc, ok := a.documentOr404(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, a.flashcards.ListByDocument(doc.ID))
+}
+
+func (a *API) handleDueReviews(c *gin.Context) {
+	limit, ok := parseLimit(c, defaultDueReviewSize)
+	if !ok {
+		return
+	}
+
+	var allow func(string) bool
+	if documentID := strings.TrimSpace(c.Query("documentId")); documentID != "" {
+		allow = func(id string) bool { return id == documentID }
+	} else if folderID := strings.TrimSpace(c.Query("folderId")); folderID != "" {
+		folder, err := a.store.GetFolder(folderID)
+		if err != nil {
+			respondMessage(c, http.StatusNotFound, "folder not found")
+			return
+		}
+		inFolder := make(map[string]bool, len(folder.DocumentIDs))
+		for _, id := range folder.DocumentIDs {
+			inFolder[id] = true
+		}
+		allow = func(id string) bool { return inFolder[id] }
+	}
+
+	c.JSON(http.StatusOK, a.flashcards.Due(time.Now().Unix(), allow, limit))
+}
+
+func (a *API) handleReviewFlashcard(c *gin.Context) {
+	var payload struct {
+		Grade *int `json:"grade"`
+	}
+	if err := c.ShouldBindJSON(&payload); err != nil || payload.Grade == nil {
+		respondMessage(c, http.StatusBadRequest, "grade is required")
+		return
+	}
+
+	card, err := a.flashcards.Review(c.Param("id"), func(card domain.Flashcard) (domain.Flashcard, error) {
+		return review.Schedule(card, *payload.Grade, time.Now())
+	})
+	switch {
+	case errors.Is(err, storage.ErrFlashcardNotFound):
+		respondMessage(c, http.StatusNotFound, "flashcard not found")
+		return
+	case errors.Is(err, review.ErrInvalidGrade):
+		respondError(c, http.StatusBadRequest, err)
+		return
+	case err != nil:
+		respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, card)
+}
+
+func (a *API) generateFlashcards(ctx context.Context, doc domain.Document, source, content string, count int, replace bool) ([]domain.Flashcard, error) {
+	raw, err := a.generator.GenerateFlashcards(ctx, content, count)
+	if err != nil {
+		log.Printf("flashcard generation failed: %v", err)
+		return nil, err
+	}
+	cards, err := services.ParseFlashcards(raw, count)
+	if err != nil {
+		log.Printf("invalid flashcards returned for document %s: %v", doc.ID, err)
+		return nil, fmt.Errorf("%w: %v", errInvalidFlashcards, err)
+	}
+
+	now := time.Now()
+	for i := range cards {
+		cards[i] = review.NewCard(cards[i], now)
+		cards[i].Source = source
+	}
+	return a.flashcards.AddCards(doc.ID, cards, replace)
+}
+
+func flashcardSource(doc domain.Document, requested string) (string, string, error) {
+	switch strings.TrimSpace(requested) {
+	case "":
+		if strings.TrimSpace(doc.Course) != "" {
+			return domain.FlashcardSourceCourse, doc.Course, nil
+		}
+		if strings.TrimSpace(doc.Summary) != "" {
+			return domain.FlashcardSourceSummary, doc.Summary, nil
+		}
+		return "", "", errors.New("document has no course or summary")
+	case domain.FlashcardSourceCourse:
+		if strings.TrimSpace(doc.Course) != "" {
+			return domain.FlashcardSourceCourse, doc.Course, nil
+		}
+		return "", "", errors.New("document has no course")
+	case domain.FlashcardSourceSummary:
+		if strings.TrimSpace(doc.Summary) != "" {
+			return domain.FlashcardSourceSummary, doc.Summary, nil
+		}
+		return "", "", errors.New("document has no summary")
+	default:
+		return "", "", errors.New("source must be course or summary")
+	}
+}
@@ -0,0 +1,182 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"myProfessor/internal/config"
+	"myProfessor/internal/domain"
+	"myProfessor/internal/services"
+	"myProfessor/internal/storage"
+)
+
+func (a *API) handleGetJob(c *gin.Context) {
+	job, err := a.jobs.Get(c.Param("id"))
+	if err != nil {
+		respondMessage(c, http.StatusNotFound, "job not found")
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
+
+func (a *API) runTranscribeJob(ctx context.Context, job domain.Job) error {
+	doc, err := a.store.GetDocument(job.DocumentID)
+	if err != nil {
+		return err
+	}
+
+	doc.ProcessingStatus = domain.ProcessingStatusProcessing
+	doc.ProcessingError = ""
+	doc.ChunksTotal = 0
+	doc.ChunksDone = 0
+	if doc, err = a.store.UpdateDocument(doc); err != nil {
+		return err
+	}
+
+	var transcript services.Transcript
+	if a.localTranscription() {
+		transcript, err = a.transcribeLocally(ctx, doc)
+	} else {
+		doc, transcript, err = a.transcribeRemotely(ctx, doc)
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if err != nil {
+		return a.failDocument(doc, err)
+	}
+
+	doc.Transcription = transcript.Text
+	doc.Segments = transcript.Segments
+	doc.ProcessingStatus = domain.ProcessingStatusCompleted
+	doc.ProcessingError = ""
+	if _, err := a.store.UpdateDocument(doc); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (a *API) transcribeRemotely(ctx context.Context, doc domain.Document) (domain.Document, services.Transcript, error) {
+	doc, audioPath, err := a.prepareAudio(doc)
+	if errors.Is(err, storage.ErrExceedsWhisperLimit) {
+		log.Printf("document %s exceeds the Whisper limit, transcribing in chunks", doc.ID)
+		return a.transcribeChunks(ctx, doc)
+	}
+	if err != nil {
+		return doc, services.Transcript{}, err
+	}
+
+	transcript, err := a.transcriber.TranscribeAudio(ctx, audioPath)
+	if err != nil {
+		return doc, services.Transcript{}, fmt.Errorf("transcription failed: %w", err)
+	}
+	return doc, transcript, nil
+}
+
+func (a *API) localTranscription() bool {
+	return a.cfg.TranscriptionProvider == config.TranscriptionProviderWhisperCpp
+}
+
+func (a *API) transcribeLocally(ctx context.Context, doc domain.Document) (services.Transcript, error) {
+	pcmPath, err := a.files.ConvertToPCM(sourceAudioPath(doc))
+	if err != nil {
+		return services.Transcript{}, fmt.Errorf("audio conversion failed: %w", err)
+	}
+	defer os.Remove(pcmPath)
+
+	transcript, err := a.transcriber.TranscribeAudio(ctx, pcmPath)
+	if err != nil {
+		return services.Transcript{}, fmt.Errorf("transcription failed: %w", err)
+	}
+	return transcript, nil
+}
+
+func (a *API) transcribeChunks(ctx context.Context, doc domain.Document) (domain.Document, services.Transcript, error) {
+	chunks, err := a.files.SplitAudio(sourceAudioPath(doc))
+	if err != nil {
+		return doc, services.Transcript{}, fmt.Errorf("audio splitting failed: %w", err)
+	}
+	defer a.files.RemoveChunks(chunks)
+
+	doc.ChunksTotal = len(chunks)
+	doc.ChunksDone = 0
+	if doc, err = a.store.UpdateDocument(doc); err != nil {
+		return doc, services.Transcript{}, err
+	}
+
+	parts := make([]string, 0, len(chunks))
+	segments := make([]domain.Segment, 0)
+	for i, chunk := range chunks {
+		transcript, err := a.transcriber.TranscribeAudio(ctx, chunk.Path)
+		if err != nil {
+			return doc, services.Transcript{}, fmt.Errorf("transcription of chunk %d/%d failed: %w", i+1, len(chunks), err)
+		}
+		parts = append(parts, transcript.Text)
+		segments = services.MergeSegments(segments, services.ShiftSegments(transcript.Segments, chunk.Start))
+
+		if err := ctx.Err(); err != nil {
+			return doc, services.Transcript{}, err
+		}
+		doc.ChunksDone = i + 1
+		if doc, err = a.store.UpdateDocument(doc); err != nil {
+			return doc, services.Transcript{}, err
+		}
+	}
+
+	return doc, services.Transcript{Text: services.MergeTranscripts(parts), Segments: segments}, nil
+}
+
+func (a *API) prepareAudio(doc domain.Document) (domain.Document, string, error) {
+	sourcePath := sourceAudioPath(doc)
+	if sourcePath == "" {
+		return doc, "", errors.New("no audio available for transcription")
+	}
+
+	compressedPath := strings.TrimSpace(doc.AudioPath)
+	needsCompression := compressedPath == "" || compressedPath == sourcePath
+	if !needsCompression {
+		if _, err := os.Stat(compressedPath); err != nil {
+			log.Printf("compressed audio missing, re-creating: %v", err)
+			needsCompression = true
+		}
+	}
+
+	if needsCompression {
+		newPath, err := a.files.CompressAudio(sourcePath)
+		if err != nil {
+			return doc, "", fmt.Errorf("audio compression failed: %w", err)
+		}
+		doc.AudioPath = newPath
+		if doc.OriginalAudioPath == "" && newPath != sourcePath {
+			doc.OriginalAudioPath = sourcePath
+		}
+		compressedPath = newPath
+	}
+
+	return doc, compressedPath, nil
+}
+
+func sourceAudioPath(doc domain.Document) string {
+	if path := strings.TrimSpace(doc.OriginalAudioPath); path != "" {
+		return path
+	}
+	return strings.TrimSpace(doc.AudioPath)
+}
+
+func (a *API) failDocument(doc domain.Document, cause error) error {
+	doc.ProcessingStatus = domain.ProcessingStatusFailed
+	doc.ProcessingError = cause.Error()
+	if _, err := a.store.UpdateDocument(doc); err != nil {
+		log.Printf("failed to persist failed processing state: %v", err)
+	}
+	return cause
+}
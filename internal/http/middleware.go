@@ -1,8 +1,10 @@
 package http
 
 import (
+	"crypto/subtle"
 	"log"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/gin-contrib/cors"
@@ -15,7 +17,7 @@ func CORS() gin.HandlerFunc {
 			"http://localhost:8080",
 			"http://localhost:5173",
 		},
-		AllowMethods:     []string{"GET", "POST", "PATCH", "DELETE", "OPTIONS"},
+		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
 		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization"},
 		ExposeHeaders:    []string{"Content-Length"},
 		AllowCredentials: false,
@@ -43,3 +45,21 @@ func MaxBodySize(limit int64) gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+func AdminAuth(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if token == "" {
+			respondMessage(c, http.StatusForbidden, "admin endpoints are disabled")
+			c.Abort()
+			return
+		}
+
+		provided, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+			respondMessage(c, http.StatusUnauthorized, "invalid admin token")
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,57 @@
+package http
+
+import (
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"myProfessor/internal/domain"
+	"myProfessor/internal/services"
+)
+
+func (a *API) handleListPDFTemplates(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"templates": a.pdf.Templates()})
+}
+
+func (a *API) handleSetFolderPDFTemplate(c *gin.Context) {
+	var payload struct {
+		Template string `json:"template"`
+	}
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		respondMessage(c, http.StatusBadRequest, "invalid payload")
+		return
+	}
+
+	payload.Template = strings.TrimSpace(payload.Template)
+	if payload.Template != "" {
+		if _, err := a.pdf.Template(payload.Template); err != nil {
+			respondMessage(c, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	folder, err := a.store.SetFolderPDFTemplate(c.Param("id"), payload.Template)
+	if err != nil {
+		status := http.StatusNotFound
+		if !strings.Contains(err.Error(), "not found") {
+			status = http.StatusInternalServerError
+		}
+		respondMessage(c, status, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, folder)
+}
+
+func (a *API) folderPDFTemplate(folder domain.Folder) string {
+	if folder.PDFTemplate == "" {
+		return ""
+	}
+	if _, err := a.pdf.Template(folder.PDFTemplate); err != nil {
+		log.Printf("folder %s uses missing pdf template %q, falling back to %s", folder.ID, folder.PDFTemplate, services.DefaultPDFTemplate)
+		return ""
+	}
+	return folder.PDFTemplate
+}
@@ -0,0 +1,240 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"myProfessor/internal/domain"
+	"myProfessor/internal/jobs"
+	"myProfessor/internal/services"
+)
+
+var errNoTranscription = errors.New("document has no transcription")
+
+func parsePipelineSteps(c *gin.Context) ([]string, error) {
+	if strings.EqualFold(strings.TrimSpace(c.Query("pipeline")), "full") {
+		return slices.Clone(domain.PipelineSteps), nil
+	}
+
+	raw := strings.TrimSpace(c.PostForm("steps"))
+	if raw == "" {
+		raw = strings.TrimSpace(c.Query("steps"))
+	}
+	if raw == "" {
+		return nil, nil
+	}
+
+	requested := map[string]bool{}
+	for _, step := range strings.Split(raw, ",") {
+		step = strings.ToLower(strings.TrimSpace(step))
+		if step == "" {
+			continue
+		}
+		if !slices.Contains(domain.PipelineSteps, step) {
+			return nil, fmt.Errorf("unknown pipeline step %q", step)
+		}
+		requested[step] = true
+	}
+
+	steps := make([]string, 0, len(requested))
+	for _, step := range domain.PipelineSteps {
+		if requested[step] {
+			steps = append(steps, step)
+		}
+	}
+	return steps, nil
+}
+
+func (a *API) startPipeline(doc domain.Document, steps []string) (domain.Document, domain.Job, error) {
+	previous := doc.Clone()
+
+	if doc.Pipeline == nil || !slices.Equal(doc.Pipeline.Steps, steps) {
+		doc.Pipeline = &domain.Pipeline{Steps: steps, Completed: []string{}}
+	}
+	doc.Pipeline.Status = domain.ProcessingStatusPending
+	doc.Pipeline.FailedStep = ""
+	doc.Pipeline.Current = ""
+	doc.ProcessingError = ""
+
+	doc, err := a.store.UpdateDocument(doc)
+	if err != nil {
+		return doc, domain.Job{}, err
+	}
+
+	job, err := a.jobs.EnqueueUnique(domain.JobTypePipeline, doc.ID)
+	if err != nil {
+		if !errors.Is(err, jobs.ErrActiveJob) {
+			a.restoreProcessingState(previous)
+		}
+		return previous, domain.Job{}, err
+	}
+	return doc, job, nil
+}
+
+func (a *API) handleResumePipeline(c *gin.Context) {
+	doc, ok := a.documentOr404(c)
+	if !ok {
+		return
+	}
+
+	if doc.Pipeline == nil {
+		respondMessage(c, http.StatusBadRequest, "document has no pipeline")
+		return
+	}
+	if doc.Pipeline.Status == domain.ProcessingStatusCompleted {
+		respondMessage(c, http.StatusConflict, "pipeline already completed")
+		return
+	}
+	if _, active := a.jobs.ActiveJob(doc.ID); active {
+		respondMessage(c, http.StatusConflict, "document is already being processed")
+		return
+	}
+
+	doc, job, err := a.startPipeline(doc, doc.Pipeline.Steps)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, jobs.ErrActiveJob):
+			respondMessage(c, http.StatusConflict, "document is already being processed")
+			return
+		case errors.Is(err, jobs.ErrQueueFull):
+			status = http.StatusServiceUnavailable
+		}
+		respondError(c, status, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"document": doc, "job": job})
+}
+
+func (a *API) runPipelineJob(ctx context.Context, job domain.Job) error {
+	doc, err := a.store.GetDocument(job.DocumentID)
+	if err != nil {
+		return err
+	}
+	if doc.Pipeline == nil {
+		return fmt.Errorf("document %s has no pipeline", doc.ID)
+	}
+
+	for _, step := range doc.Pipeline.Steps {
+		if slices.Contains(doc.Pipeline.Completed, step) {
+			continue
+		}
+
+		doc.Pipeline.Current = step
+		doc.Pipeline.Status = domain.ProcessingStatusProcessing
+		doc.Pipeline.FailedStep = ""
+		if doc, err = a.store.UpdateDocument(doc); err != nil {
+			return err
+		}
+
+		stepErr := a.runPipelineStep(ctx, job, doc, step)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		if doc, err = a.store.GetDocument(doc.ID); err != nil {
+			return err
+		}
+		if doc.Pipeline == nil {
+			return fmt.Errorf("pipeline of document %s was reset", doc.ID)
+		}
+
+		if stepErr != nil {
+			doc.Pipeline.Status = domain.ProcessingStatusFailed
+			doc.Pipeline.FailedStep = step
+			doc.Pipeline.Current = ""
+			doc.ProcessingError = fmt.Sprintf("pipeline step %s failed: %v", step, stepErr)
+			if _, err := a.store.UpdateDocument(doc); err != nil {
+				return err
+			}
+			return fmt.Errorf("pipeline step %s: %w", step, stepErr)
+		}
+
+		doc.Pipeline.Completed = append(doc.Pipeline.Completed, step)
+	}
+
+	doc.Pipeline.Current = ""
+	doc.Pipeline.Status = domain.ProcessingStatusCompleted
+	_, err = a.store.UpdateDocument(doc)
+	return err
+}
+
+func (a *API) runPipelineStep(ctx context.Context, job domain.Job, doc domain.Document, step string) error {
+	switch step {
+	case domain.PipelineStepTranscribe:
+		return a.runTranscribeJob(ctx, job)
+	case domain.PipelineStepSummarize:
+		_, err := a.summarizeDocument(ctx, doc, "")
+		return err
+	case domain.PipelineStepCourse:
+		_, err := a.generateCourse(ctx, doc, "")
+		return err
+	case domain.PipelineStepPDF:
+		_, err := a.generatePDF(doc, services.PDFOptions{})
+		return err
+	default:
+		return fmt.Errorf("unknown pipeline step %q", step)
+	}
+}
+
+func (a *API) summarizeDocument(ctx context.Context, doc domain.Document, instructions string) (domain.Document, error) {
+	if strings.TrimSpace(doc.Transcription) == "" {
+		return doc, errNoTranscription
+	}
+
+	summary, err := a.generator.SummarizeText(ctx, doc.Transcription, instructions)
+	if err != nil {
+		return doc, fmt.Errorf("summary generation failed: %w", err)
+	}
+
+	latest, err := a.store.GetDocument(doc.ID)
+	if err != nil {
+		return doc, err
+	}
+	latest.Summary = summary
+	return a.store.UpdateDocument(latest)
+}
+
+func (a *API) generateCourse(ctx context.Context, doc domain.Document, instructions string) (domain.Document, error) {
+	if strings.TrimSpace(doc.Transcription) == "" {
+		return doc, errNoTranscription
+	}
+
+	course, err := a.generator.GenerateCourse(ctx, doc.Transcription, instructions)
+	if err != nil {
+		return doc, fmt.Errorf("course generation failed: %w", err)
+	}
+
+	latest, err := a.store.GetDocument(doc.ID)
+	if err != nil {
+		return doc, err
+	}
+	latest.Course = course
+	return a.store.UpdateDocument(latest)
+}
+
+func (a *API) generatePDF(doc domain.Document, opts services.PDFOptions) (domain.Document, error) {
+	folder, _ := a.store.GetFolder(doc.FolderID)
+	if opts.Template == "" {
+		opts.Template = a.folderPDFTemplate(folder)
+	}
+
+	pdfPath := a.files.PDFPath(doc.ID)
+	if err := a.pdf.GeneratePDF(doc, folder, pdfPath, opts); err != nil {
+		return doc, err
+	}
+
+	latest, err := a.store.GetDocument(doc.ID)
+	if err != nil {
+		return doc, err
+	}
+	latest.PDFPath = pdfPath
+	return a.store.UpdateDocument(latest)
+}
@@ -0,0 +1,216 @@
+package http
+
+import (
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"myProfessor/internal/domain"
+	"myProfessor/internal/services"
+)
+
+const (
+	defaultQuizQuestions = 8
+	maxQuizQuestions     = 20
+	quizGenerationTries  = 2
+	shortAnswerPassScore = 0.5
+)
+
+func (a *API) handleGenerateQuiz(c *gin.Context) {
+	doc, ok := a.documentOr404(c)
+	if !ok {
+		return
+	}
+	if strings.TrimSpace(doc.Transcription) == "" {
+		respondMessage(c, http.StatusBadRequest, errNoTranscription.Error())
+		return
+	}
+
+	var payload struct {
+		Count        int    `json:"count"`
+		Instructions string `json:"instructions"`
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&payload); err != nil {
+			respondMessage(c, http.StatusBadRequest, "invalid payload")
+			return
+		}
+	}
+	if payload.Count == 0 {
+		payload.Count = defaultQuizQuestions
+	}
+	if payload.Count < 1 || payload.Count > maxQuizQuestions {
+		respondMessage(c, http.StatusBadRequest, "count must be between 1 and 20")
+		return
+	}
+
+	var (
+		title       string
+		questions   []domain.QuizQuestion
+		lastErr     error
+		providerErr error
+	)
+	for try := 0; try < quizGenerationTries; try++ {
+		raw, err := a.generator.GenerateQuiz(c.Request.Context(), doc.Transcription, payload.Count, payload.Instructions)
+		if err != nil {
+			log.Printf("quiz generation failed for document %s: %v", doc.ID, err)
+			lastErr, providerErr = err, err
+			continue
+		}
+		providerErr = nil
+		title, questions, lastErr = services.ParseQuiz(raw, payload.Count)
+		if lastErr == nil {
+			break
+		}
+		log.Printf("invalid quiz returned for document %s: %v", doc.ID, lastErr)
+	}
+	if providerErr != nil {
+		respondMessage(c, http.StatusInternalServerError, providerErr.Error())
+		return
+	}
+	if lastErr != nil {
+		respondMessage(c, http.StatusBadGateway, "invalid quiz returned by model: "+lastErr.Error())
+		return
+	}
+	if title == "" {
+		title = "Quiz - " + doc.Title
+	}
+
+	quiz, err := a.quizzes.CreateQuiz(domain.Quiz{DocumentID: doc.ID, Title: title, Questions: questions})
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusCreated, quiz)
+}
+
+func (a *API) handleListQuizzes(c *gin.Context) {
+	doc, ok := a.documentOr404(c)
+	if !ok {
+		return
+	}
+
+	quizzes := a.quizzes.ListQuizzesByDocument(doc.ID)
+	if c.Query("includeAnswers") != "true" {
+		for i := range quizzes {
+			quizzes[i] = withoutAnswers(quizzes[i])
+		}
+	}
+	c.JSON(http.StatusOK, quizzes)
+}
+
+func (a *API) handleGetQuiz(c *gin.Context) {
+	quiz, err := a.quizzes.GetQuiz(c.Param("id"))
+	if err != nil {
+		respondMessage(c, http.StatusNotFound, "quiz not found")
+		return
+	}
+
+	if c.Query("includeAnswers") != "true" {
+		quiz = withoutAnswers(quiz)
+	}
+	c.JSON(http.StatusOK, quiz)
+}
+
+func (a *API) handleSubmitQuizAttempt(c *gin.Context) {
+	quiz, err := a.quizzes.GetQuiz(c.Param("id"))
+	if err != nil {
+		respondMessage(c, http.StatusNotFound, "quiz not found")
+		return
+	}
+
+	var payload struct {
+		Answers []struct {
+			QuestionID string `json:"questionId"`
+			Choice     *int   `json:"choice"`
+			Text       string `json:"text"`
+		} `json:"answers"`
+	}
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		respondMessage(c, http.StatusBadRequest, "invalid payload")
+		return
+	}
+
+	questions := make(map[string]domain.QuizQuestion, len(quiz.Questions))
+	for _, question := range quiz.Questions {
+		questions[question.ID] = question
+	}
+	given := make(map[string]domain.QuizAnswer, len(payload.Answers))
+	for _, answer := range payload.Answers {
+		if _, ok := questions[answer.QuestionID]; !ok {
+			respondMessage(c, http.StatusBadRequest, "unknown question "+answer.QuestionID)
+			return
+		}
+		given[answer.QuestionID] = domain.QuizAnswer{QuestionID: answer.QuestionID, Choice: answer.Choice, Text: strings.TrimSpace(answer.Text)}
+	}
+
+	attempt := domain.QuizAttempt{QuizID: quiz.ID, Answers: make([]domain.QuizAnswer, 0, len(quiz.Questions))}
+	for _, question := range quiz.Questions {
+		answer, ok := given[question.ID]
+		if !ok {
+			answer = domain.QuizAnswer{QuestionID: question.ID}
+		}
+		if err := a.gradeAnswer(c, question, &answer); err != nil {
+			log.Printf("grading of quiz %s failed: %v", quiz.ID, err)
+			respondError(c, http.StatusBadGateway, err)
+			return
+		}
+		attempt.Answers = append(attempt.Answers, answer)
+		attempt.Score += answer.Score
+		attempt.MaxScore++
+	}
+
+	attempt, err = a.quizzes.AddAttempt(attempt)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusCreated, attempt)
+}
+
+func (a *API) handleListQuizAttempts(c *gin.Context) {
+	attempts, err := a.quizzes.ListAttempts(c.Param("id"))
+	if err != nil {
+		respondMessage(c, http.StatusNotFound, "quiz not found")
+		return
+	}
+	c.JSON(http.StatusOK, attempts)
+}
+
+func (a *API) gradeAnswer(c *gin.Context, question domain.QuizQuestion, answer *domain.QuizAnswer) error {
+	switch question.Type {
+	case domain.QuizQuestionMultipleChoice:
+		answer.Correct = answer.Choice != nil && question.AnswerIndex != nil && *answer.Choice == *question.AnswerIndex
+		if answer.Correct {
+			answer.Score = 1
+		}
+		answer.Feedback = question.Explanation
+	case domain.QuizQuestionShortAnswer:
+		if answer.Text == "" {
+			answer.Feedback = question.Explanation
+			return nil
+		}
+		score, feedback, err := a.generator.GradeAnswer(c.Request.Context(), question.Prompt, question.Answer, answer.Text)
+		if err != nil {
+			return err
+		}
+		answer.Score = score
+		answer.Correct = score >= shortAnswerPassScore
+		answer.Feedback = feedback
+		if answer.Feedback == "" {
+			answer.Feedback = question.Explanation
+		}
+	}
+	return nil
+}
+
+func withoutAnswers(quiz domain.Quiz) domain.Quiz {
+	for i := range quiz.Questions {
+		quiz.Questions[i].AnswerIndex = nil
+		quiz.Questions[i].Answer = ""
+		quiz.Questions[i].Explanation = ""
+	}
+	return quiz
+}
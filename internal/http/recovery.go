@@ -0,0 +1,175 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"myProfessor/internal/domain"
+	"myProfessor/internal/jobs"
+)
+
+const (
+	maxInterruptedAttempts = 2
+	orphanSweepInterval    = 5 * time.Minute
+	cancelWaitTimeout      = 30 * time.Second
+)
+
+var errProcessingInterrupted = errors.New("processing was interrupted by a server restart, please retry")
+
+func (a *API) recoverInterruptedWork() {
+	for _, job := range a.jobs.InterruptProcessing() {
+		log.Printf("job %s (%s) for document %s was interrupted", job.ID, job.Type, job.DocumentID)
+	}
+
+	a.reconcileOrphans(time.Now())
+}
+
+func (a *API) sweepOrphans(ctx context.Context) {
+	if a.cfg.OrphanAfter <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(min(a.cfg.OrphanAfter, orphanSweepInterval))
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			a.reconcileOrphans(time.Now().Add(-a.cfg.OrphanAfter))
+		}
+	}
+}
+
+func (a *API) reconcileOrphans(staleBefore time.Time) {
+	for _, doc := range a.store.ListDocuments() {
+		if doc.UpdatedAt > staleBefore.Unix() {
+			continue
+		}
+		pipelineRunning := doc.Pipeline != nil && (doc.Pipeline.Status == domain.ProcessingStatusProcessing || doc.Pipeline.Status == domain.ProcessingStatusPending)
+		if doc.ProcessingStatus != domain.ProcessingStatusProcessing && !pipelineRunning {
+			continue
+		}
+		if _, active := a.jobs.ActiveJob(doc.ID); active {
+			continue
+		}
+
+		jobType := domain.JobTypeTranscribe
+		if pipelineRunning {
+			jobType = domain.JobTypePipeline
+		}
+
+		if a.cfg.RequeueInterrupted && hasAudio(doc) && a.interruptedAttempts(doc.ID) < maxInterruptedAttempts {
+			job, err := a.jobs.Enqueue(jobType, doc.ID)
+			if err == nil {
+				if doc.ProcessingStatus == domain.ProcessingStatusProcessing {
+					doc.ProcessingStatus = domain.ProcessingStatusPending
+				}
+				if pipelineRunning {
+					doc.Pipeline.Status = domain.ProcessingStatusPending
+					doc.Pipeline.Current = ""
+				}
+				doc.ProcessingError = ""
+				if _, err := a.store.UpdateDocument(doc); err != nil {
+					log.Printf("failed to persist re-queued document %s: %v", doc.ID, err)
+				}
+				log.Printf("document %s re-queued as %s job %s after interruption", doc.ID, jobType, job.ID)
+				continue
+			}
+			log.Printf("unable to re-queue document %s: %v", doc.ID, err)
+		}
+
+		log.Printf("document %s marked as failed after interruption", doc.ID)
+		if pipelineRunning {
+			doc.Pipeline.Status = domain.ProcessingStatusFailed
+			doc.Pipeline.FailedStep = doc.Pipeline.Current
+			doc.Pipeline.Current = ""
+		}
+		if doc.ProcessingStatus == domain.ProcessingStatusProcessing {
+			doc.ProcessingStatus = domain.ProcessingStatusFailed
+		}
+		doc.ProcessingError = errProcessingInterrupted.Error()
+		if _, err := a.store.UpdateDocument(doc); err != nil {
+			log.Printf("failed to persist interrupted document %s: %v", doc.ID, err)
+		}
+	}
+}
+
+func (a *API) interruptedAttempts(documentID string) int {
+	count := 0
+	for _, job := range a.jobs.ListByDocument(documentID) {
+		if job.Error == jobs.ErrInterrupted.Error() {
+			count++
+		}
+	}
+	return count
+}
+
+func (a *API) handleResetDocument(c *gin.Context) {
+	doc, err := a.store.GetDocument(c.Param("id"))
+	if err != nil {
+		respondMessage(c, http.StatusNotFound, "document not found")
+		return
+	}
+
+	if job, active := a.jobs.ActiveJob(doc.ID); active {
+		if err := a.jobs.Cancel(job.ID); err != nil {
+			respondError(c, http.StatusInternalServerError, err)
+			return
+		}
+		ctx, cancel := context.WithTimeout(c.Request.Context(), cancelWaitTimeout)
+		err := a.jobs.Wait(ctx, job.ID)
+		cancel()
+		if err != nil {
+			respondMessage(c, http.StatusConflict, "job is still stopping, please retry")
+			return
+		}
+		if doc, err = a.store.GetDocument(doc.ID); err != nil {
+			respondMessage(c, http.StatusNotFound, "document not found")
+			return
+		}
+	}
+
+	doc.ProcessingStatus = domain.ProcessingStatusPending
+	if strings.TrimSpace(doc.Transcription) != "" {
+		doc.ProcessingStatus = domain.ProcessingStatusCompleted
+	}
+	doc.ProcessingError = ""
+	if doc.Pipeline != nil && doc.Pipeline.Status != domain.ProcessingStatusCompleted {
+		doc.Pipeline.Status = domain.ProcessingStatusFailed
+		if doc.Pipeline.Current != "" {
+			doc.Pipeline.FailedStep = doc.Pipeline.Current
+		}
+		doc.Pipeline.Current = ""
+	}
+	if doc, err = a.store.UpdateDocument(doc); err != nil {
+		respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"document": doc})
+}
+
+func (a *API) restoreProcessingState(previous domain.Document) {
+	doc, err := a.store.GetDocument(previous.ID)
+	if err != nil {
+		return
+	}
+
+	doc.ProcessingStatus = previous.ProcessingStatus
+	doc.ProcessingError = previous.ProcessingError
+	doc.Pipeline = previous.Pipeline
+	if _, err := a.store.UpdateDocument(doc); err != nil {
+		log.Printf("failed to restore processing state of document %s: %v", doc.ID, err)
+	}
+}
+
+func hasAudio(doc domain.Document) bool {
+	return strings.TrimSpace(doc.OriginalAudioPath) != "" || strings.TrimSpace(doc.AudioPath) != ""
+}
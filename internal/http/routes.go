@@ -1,6 +1,8 @@
 package http
 
 import (
+	"errors"
+	"fmt"
 	"log"
 	"net/http"
 	"os"
@@ -13,46 +15,148 @@ import (
 
 	"myProfessor/internal/config"
 	"myProfessor/internal/domain"
+	"myProfessor/internal/events"
+	"myProfessor/internal/jobs"
+	"myProfessor/internal/search"
+	"myProfessor/internal/semantic"
 	"myProfessor/internal/services"
 	"myProfessor/internal/storage"
 )
 
 type API struct {
-	cfg    config.Config
-	files  *storage.FileManager
-	store  *storage.Store
-	openai *services.OpenAIService
-	pdf    *services.PDFService
-	share  *services.ShareService
+	cfg           config.Config
+	files         *storage.FileManager
+	store         storage.Store
+	transcriber   services.Transcriber
+	generator     services.TextGenerator
+	pdf           *services.PDFService
+	share         *services.ShareService
+	jobs          *jobs.Queue
+	events        *events.Broker
+	search        *search.Index
+	semantic      *semantic.Index
+	conversations *storage.ConversationStore
+	quizzes       *storage.QuizStore
+	flashcards    *storage.FlashcardStore
 }
 
-func NewAPI(cfg config.Config, fm *storage.FileManager, store *storage.Store, openai *services.OpenAIService, pdf *services.PDFService, share *services.ShareService) *API {
-	return &API{cfg: cfg, files: fm, store: store, openai: openai, pdf: pdf, share: share}
+type APIDeps struct {
+	Files         *storage.FileManager
+	Store         storage.Store
+	Transcriber   services.Transcriber
+	Generator     services.TextGenerator
+	PDF           *services.PDFService
+	Share         *services.ShareService
+	Jobs          *jobs.Queue
+	Semantic      *semantic.Index
+	Conversations *storage.ConversationStore
+	Quizzes       *storage.QuizStore
+	Flashcards    *storage.FlashcardStore
+}
+
+func NewAPI(cfg config.Config, deps APIDeps) *API {
+	api := &API{
+		cfg:           cfg,
+		files:         deps.Files,
+		store:         deps.Store,
+		transcriber:   deps.Transcriber,
+		generator:     deps.Generator,
+		pdf:           deps.PDF,
+		share:         deps.Share,
+		jobs:          deps.Jobs,
+		events:        events.NewBroker(),
+		search:        search.NewIndex(),
+		semantic:      deps.Semantic,
+		conversations: deps.Conversations,
+		quizzes:       deps.Quizzes,
+		flashcards:    deps.Flashcards,
+	}
+	api.store.OnDocumentChange(api.handleDocumentChange)
+	api.search.Rebuild(api.store.ListDocuments())
+	if api.semantic != nil {
+		api.semantic.Sync(api.store.ListDocuments())
+	}
+	api.jobs.Register(domain.JobTypeTranscribe, api.runTranscribeJob)
+	api.jobs.Register(domain.JobTypePipeline, api.runPipelineJob)
+	return api
+}
+
+var subtitleContentTypes = map[string]string{
+	services.SubtitleFormatSRT: "application/x-subrip; charset=utf-8",
+	services.SubtitleFormatVTT: "text/vtt; charset=utf-8",
+}
+
+func (a *API) handleDocumentChange(doc domain.Document, deleted bool) {
+	a.search.Apply(doc, deleted)
+	if a.semantic != nil {
+		a.semantic.Apply(doc, deleted)
+	}
+	a.events.Publish(doc, deleted)
 }
 
 func registerRoutes(r *gin.Engine, api *API) {
 	apiGroup := r.Group("/api")
 	{
 		apiGroup.GET("/health", api.handleHealth)
+		apiGroup.GET("/search", api.handleSearch)
+		apiGroup.GET("/search/semantic", api.handleSemanticSearch)
 
 		apiGroup.GET("/folders", api.handleListFolders)
 		apiGroup.POST("/folders", api.handleCreateFolder)
 		apiGroup.PATCH("/folders/:id", api.handleRenameFolder)
 		apiGroup.DELETE("/folders/:id", api.handleDeleteFolder)
+		apiGroup.PUT("/folders/:id/pdf-template", api.handleSetFolderPDFTemplate)
+		apiGroup.POST("/folders/:id/pdf", api.handleGenerateFolderPDF)
+		apiGroup.POST("/folders/:id/share", api.handleShareFolder)
 
 		apiGroup.GET("/folders/:id/documents", api.handleListDocumentsByFolder)
+		apiGroup.GET("/folders/:id/export/anki", api.handleExportAnki)
+		apiGroup.POST("/folders/:id/chat", api.handleFolderChat)
+		apiGroup.GET("/folders/:id/chat", api.handleGetConversation(domain.ConversationScopeFolder))
+		apiGroup.DELETE("/folders/:id/chat", api.handleDeleteConversation(domain.ConversationScopeFolder))
 		apiGroup.POST("/folders/:id/documents/upload", api.handleUploadDocument)
 
 		apiGroup.GET("/documents/:id", api.handleGetDocument)
+		apiGroup.GET("/documents/:id/export", api.handleExportDocument)
+		apiGroup.GET("/documents/:id/related", api.handleRelatedDocuments)
+		apiGroup.POST("/documents/:id/chat", api.handleDocumentChat)
+		apiGroup.GET("/documents/:id/chat", api.handleGetConversation(domain.ConversationScopeDocument))
+		apiGroup.DELETE("/documents/:id/chat", api.handleDeleteConversation(domain.ConversationScopeDocument))
+		apiGroup.GET("/documents/:id/events", api.handleDocumentEvents)
+		apiGroup.POST("/documents/:id/quiz", api.handleGenerateQuiz)
+		apiGroup.GET("/documents/:id/quizzes", api.handleListQuizzes)
+		apiGroup.POST("/documents/:id/flashcards", api.handleGenerateFlashcards)
+		apiGroup.GET("/documents/:id/flashcards", api.handleListFlashcards)
 		apiGroup.DELETE("/documents/:id", api.handleDeleteDocument)
 		apiGroup.POST("/documents/:id/pdf", api.handleGeneratePDF)
 		apiGroup.POST("/documents/:id/share", api.handleShareDocument)
 		apiGroup.POST("/documents/:id/transcribe", api.handleTranscribeDocument)
 		apiGroup.POST("/documents/:id/course", api.handleGenerateCourse)
+		apiGroup.POST("/documents/:id/course/stream", api.handleStreamCourse)
+		apiGroup.POST("/documents/:id/summary", api.handleGenerateSummary)
 		apiGroup.PATCH("/documents/:id/content", api.handleUpdateContent)
+		apiGroup.POST("/documents/:id/pipeline/resume", api.handleResumePipeline)
+
+		apiGroup.GET("/jobs/:id", api.handleGetJob)
+
+		apiGroup.GET("/pdf-templates", api.handleListPDFTemplates)
+
+		apiGroup.GET("/quizzes/:id", api.handleGetQuiz)
+		apiGroup.GET("/quizzes/:id/attempts", api.handleListQuizAttempts)
+		apiGroup.POST("/quizzes/:id/attempts", api.handleSubmitQuizAttempt)
+
+		apiGroup.GET("/reviews/due", api.handleDueReviews)
+		apiGroup.POST("/flashcards/:id/review", api.handleReviewFlashcard)
+
+	}
+
+	adminGroup := apiGroup.Group("/admin", AdminAuth(api.cfg.AdminToken))
+	{
+		adminGroup.POST("/documents/:id/reset", api.handleResetDocument)
 	}
 
 	r.GET("/pdf/:id", api.handleServePDF)
+	r.GET("/pdf/folders/:id", api.handleServeFolderPDF)
 }
 
 func (a *API) handleHealth(c *gin.Context) {
@@ -107,6 +211,7 @@ func (a *API) handleRenameFolder(c *gin.Context) {
 }
 
 func (a *API) handleDeleteFolder(c *gin.Context) {
+	docs := a.store.ListDocumentsByFolder(c.Param("id"))
 	if err := a.store.DeleteFolder(c.Param("id")); err != nil {
 		status := http.StatusNotFound
 		if !strings.Contains(err.Error(), "not found") {
@@ -115,6 +220,13 @@ func (a *API) handleDeleteFolder(c *gin.Context) {
 		respondMessage(c, status, err.Error())
 		return
 	}
+	if err := a.conversations.Delete(domain.ConversationScopeFolder, c.Param("id")); err != nil {
+		log.Printf("failed to delete conversation of folder %s: %v", c.Param("id"), err)
+	}
+	_ = os.Remove(a.files.FolderPDFPath(c.Param("id")))
+	for _, doc := range docs {
+		a.deleteDocumentData(doc.ID)
+	}
 
 	c.Status(http.StatusNoContent)
 }
@@ -130,15 +242,55 @@ func (a *API) handleListDocumentsByFolder(c *gin.Context) {
 }
 
 func (a *API) handleGetDocument(c *gin.Context) {
-	doc, err := a.store.GetDocument(c.Param("id"))
-	if err != nil {
-		respondMessage(c, http.StatusNotFound, "document not found")
+	doc, ok := a.documentOr404(c)
+	if !ok {
 		return
 	}
 
 	c.JSON(http.StatusOK, doc)
 }
 
+func (a *API) handleExportDocument(c *gin.Context) {
+	doc, ok := a.documentOr404(c)
+	if !ok {
+		return
+	}
+
+	format := strings.ToLower(strings.TrimSpace(c.DefaultQuery("format", services.SubtitleFormatSRT)))
+	contentType, ok := subtitleContentTypes[format]
+	if !ok {
+		respondMessage(c, http.StatusBadRequest, "invalid format (expected srt or vtt)")
+		return
+	}
+
+	if len(doc.Segments) == 0 {
+		respondMessage(c, http.StatusConflict, "document has no timed transcription")
+		return
+	}
+
+	content, err := services.RenderSubtitles(doc.Segments, format)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	filename := strings.TrimSpace(doc.Title)
+	if filename == "" {
+		filename = doc.ID
+	}
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename+"."+format))
+	c.Data(http.StatusOK, contentType, []byte(content))
+}
+
+func (a *API) documentOr404(c *gin.Context) (domain.Document, bool) {
+	doc, err := a.store.GetDocument(c.Param("id"))
+	if err != nil {
+		respondMessage(c, http.StatusNotFound, "document not found")
+		return domain.Document{}, false
+	}
+	return doc, true
+}
+
 func (a *API) handleDeleteDocument(c *gin.Context) {
 	docID := c.Param("id")
 	doc, err := a.store.GetDocument(docID)
@@ -158,10 +310,23 @@ func (a *API) handleDeleteDocument(c *gin.Context) {
 	if doc.PDFPath != "" {
 		_ = os.Remove(doc.PDFPath)
 	}
+	a.deleteDocumentData(doc.ID)
 
 	c.Status(http.StatusNoContent)
 }
 
+func (a *API) deleteDocumentData(docID string) {
+	if err := a.conversations.Delete(domain.ConversationScopeDocument, docID); err != nil {
+		log.Printf("failed to delete conversation of document %s: %v", docID, err)
+	}
+	if err := a.quizzes.DeleteByDocument(docID); err != nil {
+		log.Printf("failed to delete quizzes of document %s: %v", docID, err)
+	}
+	if err := a.flashcards.DeleteByDocument(docID); err != nil {
+		log.Printf("failed to delete flashcards of document %s: %v", docID, err)
+	}
+}
+
 func (a *API) handleUploadDocument(c *gin.Context) {
 	folderID := c.Param("id")
 	if _, err := a.store.GetFolder(folderID); err != nil {
@@ -178,6 +343,12 @@ func (a *API) handleUploadDocument(c *gin.Context) {
 	}
 	log.Printf("Received upload: folder=%s filename=%s size=%d", folderID, fileHeader.Filename, fileHeader.Size)
 
+	steps, err := parsePipelineSteps(c)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
 	upload, err := fileHeader.Open()
 	if err != nil {
 		log.Printf("error opening upload: %v", err)
@@ -194,11 +365,18 @@ func (a *API) handleUploadDocument(c *gin.Context) {
 	}
 	log.Printf("Audio saved to %s", audioPath)
 
-	compressedPath, err := a.files.CompressAudio(audioPath)
+	compressedPath := audioPath
+	if !a.localTranscription() {
+		compressedPath, err = a.files.CompressAudio(audioPath)
+	}
+	if errors.Is(err, storage.ErrExceedsWhisperLimit) {
+		log.Printf("audio too long for a single request, it will be transcribed in chunks: %v", err)
+		compressedPath, err = audioPath, nil
+	}
 	if err != nil {
 		log.Printf("audio compression failed: %v", err)
 		status := http.StatusInternalServerError
-		if strings.Contains(err.Error(), "Whisper limit") || strings.Contains(err.Error(), "no audio path provided") {
+		if strings.Contains(err.Error(), "no audio path provided") {
 			status = http.StatusBadRequest
 		}
 		respondMessage(c, status, err.Error())
@@ -222,6 +400,18 @@ func (a *API) handleUploadDocument(c *gin.Context) {
 	}
 	log.Printf("Document %s created for folder %s", saved.ID, folderID)
 
+	if len(steps) > 0 {
+		saved, job, err := a.startPipeline(saved, steps)
+		if err != nil {
+			log.Printf("pipeline start failed: %v", err)
+			respondMessage(c, http.StatusInternalServerError, "document saved but pipeline could not be started")
+			return
+		}
+		log.Printf("Pipeline %v queued for document %s as job %s", steps, saved.ID, job.ID)
+		c.JSON(http.StatusAccepted, gin.H{"document": saved, "job": job})
+		return
+	}
+
 	c.JSON(http.StatusCreated, gin.H{"document": saved})
 }
 
@@ -233,21 +423,37 @@ func (a *API) handleGeneratePDF(c *gin.Context) {
 		return
 	}
 
-	folder, _ := a.store.GetFolder(doc.FolderID)
-
-	pdfPath := a.files.PDFPath(doc.ID)
-	if err := a.pdf.GeneratePDF(doc, folder, pdfPath); err != nil {
-		respondError(c, http.StatusInternalServerError, err)
+	var payload struct {
+		Sections []string `json:"sections"`
+		Template string   `json:"template"`
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&payload); err != nil {
+			respondMessage(c, http.StatusBadRequest, "invalid payload")
+			return
+		}
+	}
+	sections, err := services.ParsePDFSections(payload.Sections)
+	if err != nil {
+		respondMessage(c, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	doc.PDFPath = pdfPath
-	if _, err := a.store.UpdateDocument(doc); err != nil {
+	template := strings.TrimSpace(payload.Template)
+	if template != "" {
+		if _, err := a.pdf.Template(template); err != nil {
+			respondMessage(c, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	doc, err = a.generatePDF(doc, services.PDFOptions{Sections: sections, Template: template})
+	if err != nil {
 		respondError(c, http.StatusInternalServerError, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"pdfPath": pdfPath})
+	c.JSON(http.StatusOK, gin.H{"pdfPath": doc.PDFPath})
 }
 
 func (a *API) handleShareDocument(c *gin.Context) {
@@ -296,20 +502,48 @@ func (a *API) handleGenerateCourse(c *gin.Context) {
 		}
 	}
 
-	course, err := a.openai.GenerateCourse(doc.Transcription, payload.Instructions)
+	doc, err = a.generateCourse(c.Request.Context(), doc, payload.Instructions)
 	if err != nil {
 		log.Printf("course generation failed: %v", err)
 		respondMessage(c, http.StatusInternalServerError, err.Error())
 		return
 	}
 
-	doc.Course = course
-	if _, err := a.store.UpdateDocument(doc); err != nil {
-		respondError(c, http.StatusInternalServerError, err)
+	c.JSON(http.StatusOK, gin.H{"course": doc.Course})
+}
+
+func (a *API) handleGenerateSummary(c *gin.Context) {
+	docID := c.Param("id")
+	doc, err := a.store.GetDocument(docID)
+	if err != nil {
+		respondMessage(c, http.StatusNotFound, "document not found")
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"course": course})
+	if strings.TrimSpace(doc.Transcription) == "" {
+		respondMessage(c, http.StatusBadRequest, "document has no transcription")
+		return
+	}
+
+	var payload struct {
+		Instructions string `json:"instructions"`
+	}
+
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&payload); err != nil {
+			respondMessage(c, http.StatusBadRequest, "invalid payload")
+			return
+		}
+	}
+
+	doc, err = a.summarizeDocument(c.Request.Context(), doc, payload.Instructions)
+	if err != nil {
+		log.Printf("summary generation failed: %v", err)
+		respondMessage(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"summary": doc.Summary})
 }
 
 func (a *API) handleTranscribeDocument(c *gin.Context) {
@@ -324,114 +558,92 @@ func (a *API) handleTranscribeDocument(c *gin.Context) {
 		respondMessage(c, http.StatusConflict, "transcription already in progress")
 		return
 	}
-
-	sourcePath := strings.TrimSpace(doc.OriginalAudioPath)
-	if sourcePath == "" {
-		sourcePath = strings.TrimSpace(doc.AudioPath)
+	if _, active := a.jobs.ActiveJob(docID); active {
+		respondMessage(c, http.StatusConflict, "transcription already in progress")
+		return
 	}
 
-	if sourcePath == "" {
+	if !hasAudio(doc) {
 		respondMessage(c, http.StatusBadRequest, "no audio available for transcription")
 		return
 	}
 
-	compressedPath := strings.TrimSpace(doc.AudioPath)
-	needsCompression := compressedPath == "" || compressedPath == sourcePath
-	if !needsCompression {
-		if _, err := os.Stat(compressedPath); err != nil {
-			log.Printf("compressed audio missing, re-creating: %v", err)
-			needsCompression = true
-		}
+	previous := doc.Clone()
+	doc.ProcessingStatus = domain.ProcessingStatusPending
+	doc.ProcessingError = ""
+	if _, err := a.store.UpdateDocument(doc); err != nil {
+		respondError(c, http.StatusInternalServerError, err)
+		return
 	}
 
-	if needsCompression {
-		newPath, err := a.files.CompressAudio(sourcePath)
-		if err != nil {
-			log.Printf("audio compression failed: %v", err)
-			status := http.StatusInternalServerError
-			if strings.Contains(err.Error(), "Whisper limit") || strings.Contains(err.Error(), "no audio path provided") {
-				status = http.StatusBadRequest
-			}
-			respondMessage(c, status, err.Error())
+	job, err := a.jobs.EnqueueUnique(domain.JobTypeTranscribe, docID)
+	if err != nil {
+		if errors.Is(err, jobs.ErrActiveJob) {
+			respondMessage(c, http.StatusConflict, "transcription already in progress")
 			return
 		}
-		doc.AudioPath = newPath
-		if doc.OriginalAudioPath == "" && newPath != sourcePath {
-			doc.OriginalAudioPath = sourcePath
+		a.restoreProcessingState(previous)
+
+		status := http.StatusInternalServerError
+		if errors.Is(err, jobs.ErrQueueFull) {
+			status = http.StatusServiceUnavailable
 		}
-		compressedPath = newPath
+		respondError(c, status, err)
+		return
 	}
 
-	audioPath := compressedPath
+	c.JSON(http.StatusAccepted, gin.H{"job": job})
+}
 
-	doc.ProcessingStatus = domain.ProcessingStatusProcessing
-	doc.ProcessingError = ""
-	if doc, err = a.store.UpdateDocument(doc); err != nil {
-		respondError(c, http.StatusInternalServerError, err)
+func (a *API) handleServePDF(c *gin.Context) {
+	if !a.checkSignedURL(c) {
 		return
 	}
 
-	transcription, err := a.openai.TranscribeAudio(c.Request.Context(), audioPath)
+	docID := c.Param("id")
+	doc, err := a.store.GetDocument(docID)
 	if err != nil {
-		log.Printf("transcription failed: %v", err)
-		doc.ProcessingStatus = domain.ProcessingStatusFailed
-		doc.ProcessingError = err.Error()
-		if _, updateErr := a.store.UpdateDocument(doc); updateErr != nil {
-			log.Printf("failed to persist failed transcription state: %v", updateErr)
-		}
-		respondMessage(c, http.StatusBadGateway, "transcription failed")
+		respondMessage(c, http.StatusNotFound, "document not found")
 		return
 	}
 
-	doc.Transcription = transcription
-	doc.ProcessingStatus = domain.ProcessingStatusCompleted
-	doc.ProcessingError = ""
-	if doc, err = a.store.UpdateDocument(doc); err != nil {
-		respondError(c, http.StatusInternalServerError, err)
-		return
+	pdfPath := doc.PDFPath
+	if pdfPath == "" {
+		pdfPath = a.files.PDFPath(docID)
 	}
 
-	c.JSON(http.StatusOK, gin.H{"document": doc})
+	servePDFFile(c, pdfPath)
 }
 
-func (a *API) handleServePDF(c *gin.Context) {
-	docID := c.Param("id")
+func (a *API) checkSignedURL(c *gin.Context) bool {
 	expiresParam := c.Query("exp")
 	signature := c.Query("sig")
 
 	if expiresParam == "" || signature == "" {
 		respondMessage(c, http.StatusBadRequest, "missing signature")
-		return
+		return false
 	}
 
 	expires, err := strconv.ParseInt(expiresParam, 10, 64)
 	if err != nil {
 		respondMessage(c, http.StatusBadRequest, "invalid expiration")
-		return
+		return false
 	}
 
 	if expires < time.Now().Unix() {
 		respondMessage(c, http.StatusGone, "link expired")
-		return
+		return false
 	}
 
 	path := c.Request.URL.Path
 	if !a.share.Validate(path, expires, signature) {
 		respondMessage(c, http.StatusForbidden, "invalid signature")
-		return
-	}
-
-	doc, err := a.store.GetDocument(docID)
-	if err != nil {
-		respondMessage(c, http.StatusNotFound, "document not found")
-		return
-	}
-
-	pdfPath := doc.PDFPath
-	if pdfPath == "" {
-		pdfPath = a.files.PDFPath(docID)
+		return false
 	}
+	return true
+}
 
+func servePDFFile(c *gin.Context, pdfPath string) {
 	if _, err := os.Stat(pdfPath); err != nil {
 		respondMessage(c, http.StatusNotFound, "pdf not found")
 		return
@@ -461,7 +673,13 @@ func (a *API) handleUpdateContent(c *gin.Context) {
 
 	switch strings.ToLower(strings.TrimSpace(payload.Field)) {
 	case "transcription":
+		if payload.Content != doc.Transcription {
+			doc.Segments = nil
+		}
 		doc.Transcription = payload.Content
+		if a.semantic != nil {
+			a.semantic.Invalidate(doc.ID)
+		}
 	case "summary":
 		doc.Summary = payload.Content
 	case "course":
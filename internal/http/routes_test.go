@@ -1,9 +1,19 @@
 package http
 
 import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"context"
+	"database/sql"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -12,11 +22,129 @@ import (
 
 	"myProfessor/internal/config"
 	"myProfessor/internal/domain"
+	"myProfessor/internal/jobs"
+	"myProfessor/internal/semantic"
 	"myProfessor/internal/services"
 	"myProfessor/internal/storage"
 )
 
-func setupTestServer(t *testing.T) (*gin.Engine, *storage.Store) {
+type fakeProvider struct {
+	transcription string
+	course        string
+	summary       string
+	quizFailures  int
+	onSummarize   func()
+}
+
+func (f *fakeProvider) TranscribeAudio(ctx context.Context, path string) (services.Transcript, error) {
+	return services.Transcript{
+		Text:     f.transcription,
+		Segments: []domain.Segment{{Start: 0, End: 2.5, Text: f.transcription}},
+	}, nil
+}
+
+func (f *fakeProvider) SummarizeText(ctx context.Context, transcription, instructions string) (string, error) {
+	if f.onSummarize != nil {
+		f.onSummarize()
+	}
+	return f.summary, nil
+}
+
+func (f *fakeProvider) GenerateCourse(ctx context.Context, transcription, instructions string) (string, error) {
+	return f.course, nil
+}
+
+func (f *fakeProvider) StreamCourse(ctx context.Context, transcription, instructions string, onDelta func(string) error) (string, error) {
+	for _, word := range strings.SplitAfter(f.course, " ") {
+		if err := onDelta(word); err != nil {
+			return "", err
+		}
+	}
+	return f.course, nil
+}
+
+func (f *fakeProvider) AnswerQuestion(ctx context.Context, passages []services.Passage, history []domain.ChatMessage, question string) (string, error) {
+	if len(passages) == 0 {
+		return "Je ne sais pas.", nil
+	}
+	return fmt.Sprintf("Réponse n°%d tirée de %s [1].", len(history)/2+1, passages[0].Title), nil
+}
+
+func (f *fakeProvider) GenerateQuiz(ctx context.Context, transcription string, questionCount int, instructions string) (string, error) {
+	if f.quizFailures > 0 {
+		f.quizFailures--
+		return "", errors.New("model overloaded")
+	}
+	return `{"title": "Quiz de test", "questions": [
+		{"type": "mcq", "question": "Quelle est la bonne réponse ?", "choices": ["A", "B", "C"], "answer": 1, "explanation": "B est correct."},
+		{"type": "short", "question": "Citez le mot clé.", "answer": "transcription", "explanation": "Le mot clé est transcription."}
+	]}`, nil
+}
+
+func (f *fakeProvider) GradeAnswer(ctx context.Context, question, expected, answer string) (float64, string, error) {
+	if strings.EqualFold(strings.TrimSpace(answer), expected) {
+		return 1, "Correct.", nil
+	}
+	return 0, "Incorrect.", nil
+}
+
+func (f *fakeProvider) GenerateFlashcards(ctx context.Context, content string, count int) (string, error) {
+	return `{"cards": [
+		{"front": "Qu'est-ce qu'une valeur propre ?", "back": "Un scalaire λ tel que Av = λv."},
+		{"front": "Trace d'une matrice", "back": "La somme des coefficients diagonaux."}
+	]}`, nil
+}
+
+func (f *fakeProvider) EmbeddingModel() string {
+	return "fake-embedding"
+}
+
+func (f *fakeProvider) Embed(ctx context.Context, inputs []string) ([][]float32, error) {
+	vectors := make([][]float32, len(inputs))
+	for i, input := range inputs {
+		vector := make([]float32, 32)
+		for _, word := range strings.Fields(strings.ToLower(input)) {
+			sum := 0
+			for _, r := range word {
+				sum += int(r)
+			}
+			vector[sum%32]++
+		}
+		vectors[i] = vector
+	}
+	return vectors, nil
+}
+
+const testAdminToken = "admin-token"
+
+func setupTestServer(t *testing.T) (*gin.Engine, storage.Store) {
+	t.Helper()
+
+	return setupTestServerWithProvider(t, &fakeProvider{
+		transcription: "transcription de test",
+		summary:       "résumé de test",
+		course:        "# Cours de test",
+	})
+}
+
+func setupTestServerWithProvider(t *testing.T, provider *fakeProvider) (*gin.Engine, storage.Store) {
+	t.Helper()
+
+	api := newTestAPI(t, provider)
+
+	engine := gin.New()
+	engine.Use(gin.Recovery())
+	registerRoutes(engine, api)
+
+	api.jobs.Start()
+	t.Cleanup(api.jobs.Stop)
+	api.semantic.Start()
+	t.Cleanup(api.semantic.Stop)
+
+	return engine, api.store
+}
+
+func newTestAPI(t *testing.T, provider *fakeProvider) *API {
 	t.Helper()
 
 	tmpDir := t.TempDir()
@@ -30,6 +158,8 @@ func setupTestServer(t *testing.T) (*gin.Engine, *storage.Store) {
 		ShareTTL:              time.Minute,
 		MaxUploadBytes:        1 * 1024 * 1024,
 		DataDir:               tmpDir,
+		RequeueInterrupted:    true,
+		AdminToken:            testAdminToken,
 	}
 
 	fm, err := storage.NewFileManager(cfg.DataDir, cfg.MaxUploadBytes)
@@ -37,21 +167,78 @@ func setupTestServer(t *testing.T) (*gin.Engine, *storage.Store) {
 		t.Fatalf("file manager: %v", err)
 	}
 
-	store, err := storage.NewStore(cfg.DataDir)
+	store, err := storage.NewJSONStore(cfg.DataDir)
 	if err != nil {
 		t.Fatalf("store: %v", err)
 	}
 
-	openai := services.NewOpenAIService(cfg)
 	pdf := services.NewPDFService()
 	share := services.NewShareService(cfg)
 
-	engine := gin.New()
-	engine.Use(gin.Recovery())
-	api := NewAPI(cfg, fm, store, openai, pdf, share)
-	registerRoutes(engine, api)
+	queue, err := jobs.NewQueue(cfg.DataDir, 1, 0)
+	if err != nil {
+		t.Fatalf("job queue: %v", err)
+	}
+
+	semanticIndex, err := semantic.NewIndex(cfg.DataDir, provider)
+	if err != nil {
+		t.Fatalf("semantic index: %v", err)
+	}
+
+	conversations, err := storage.NewConversationStore(cfg.DataDir)
+	if err != nil {
+		t.Fatalf("conversation store: %v", err)
+	}
+
+	quizzes, err := storage.NewQuizStore(cfg.DataDir)
+	if err != nil {
+		t.Fatalf("quiz store: %v", err)
+	}
+
+	flashcards, err := storage.NewFlashcardStore(cfg.DataDir)
+	if err != nil {
+		t.Fatalf("flashcard store: %v", err)
+	}
+
+	return NewAPI(cfg, APIDeps{
+		Files:         fm,
+		Store:         store,
+		Transcriber:   provider,
+		Generator:     provider,
+		PDF:           pdf,
+		Share:         share,
+		Jobs:          queue,
+		Semantic:      semanticIndex,
+		Conversations: conversations,
+		Quizzes:       quizzes,
+		Flashcards:    flashcards,
+	})
+}
 
-	return engine, store
+func waitForJob(t *testing.T, engine *gin.Engine, id string) domain.Job {
+	t.Helper()
+
+	var job domain.Job
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		req := httptest.NewRequest(http.MethodGet, "/api/jobs/"+id, nil)
+		rec := httptest.NewRecorder()
+		engine.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected 200 for job lookup, got %d", rec.Code)
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), &job); err != nil {
+			t.Fatalf("decode job: %v", err)
+		}
+		if job.Status == domain.ProcessingStatusFailed || job.Status == domain.ProcessingStatusCompleted {
+			return job
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	t.Fatalf("job %s did not finish in time (status %q)", id, job.Status)
+	return job
 }
 
 func TestHealthHandler(t *testing.T) {
@@ -158,3 +345,1185 @@ func TestShareLinkValidation(t *testing.T) {
 		t.Fatalf("expected 410 for expired link, got %d", expiredRec.Code)
 	}
 }
+
+func TestTranscribeEnqueuesJob(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	engine, store := setupTestServer(t)
+
+	audioDir := t.TempDir()
+	original := filepath.Join(audioDir, "lecture.wav")
+	compressed := filepath.Join(audioDir, "lecture_compressed.mp3")
+	for _, path := range []string{original, compressed} {
+		if err := os.WriteFile(path, []byte("audio"), 0o644); err != nil {
+			t.Fatalf("write audio: %v", err)
+		}
+	}
+
+	doc, err := store.CreateDocument(domain.Document{
+		Title:             "Lecture",
+		AudioPath:         compressed,
+		OriginalAudioPath: original,
+	})
+	if err != nil {
+		t.Fatalf("create document: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/documents/"+doc.ID+"/transcribe", nil)
+	rec := httptest.NewRecorder()
+
+	engine.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d", rec.Code)
+	}
+
+	var body struct {
+		Job domain.Job `json:"job"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	if body.Job.ID == "" {
+		t.Fatalf("expected job id in response")
+	}
+
+	job := waitForJob(t, engine, body.Job.ID)
+	if job.Status != domain.ProcessingStatusCompleted {
+		t.Fatalf("expected completed job, got %q (%s)", job.Status, job.Error)
+	}
+
+	updated, err := store.GetDocument(doc.ID)
+	if err != nil {
+		t.Fatalf("get document: %v", err)
+	}
+	if updated.ProcessingStatus != domain.ProcessingStatusCompleted || updated.Transcription != "transcription de test" {
+		t.Fatalf("expected completed document with transcription, got %q %q", updated.ProcessingStatus, updated.Transcription)
+	}
+	if len(updated.Segments) != 1 || updated.Segments[0].End != 2.5 {
+		t.Fatalf("expected timed segments on document, got %+v", updated.Segments)
+	}
+}
+
+func TestResetDocumentClearsProcessingState(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	engine, store := setupTestServer(t)
+
+	doc, err := store.CreateDocument(domain.Document{
+		Title:            "Stuck",
+		ProcessingStatus: domain.ProcessingStatusProcessing,
+	})
+	if err != nil {
+		t.Fatalf("create document: %v", err)
+	}
+
+	for _, header := range []string{"", "Bearer wrong-token"} {
+		req := httptest.NewRequest(http.MethodPost, "/api/admin/documents/"+doc.ID+"/reset", nil)
+		if header != "" {
+			req.Header.Set("Authorization", header)
+		}
+		rec := httptest.NewRecorder()
+		engine.ServeHTTP(rec, req)
+		if rec.Code != http.StatusUnauthorized {
+			t.Fatalf("expected 401 with authorization %q, got %d", header, rec.Code)
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/admin/documents/"+doc.ID+"/reset", nil)
+	req.Header.Set("Authorization", "Bearer "+testAdminToken)
+	rec := httptest.NewRecorder()
+
+	engine.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+
+	updated, err := store.GetDocument(doc.ID)
+	if err != nil {
+		t.Fatalf("get document: %v", err)
+	}
+	if updated.ProcessingStatus != domain.ProcessingStatusPending {
+		t.Fatalf("expected pending status after reset, got %q", updated.ProcessingStatus)
+	}
+}
+
+func TestDeleteRemovesDocumentData(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	api := newTestAPI(t, &fakeProvider{})
+	engine := gin.New()
+	registerRoutes(engine, api)
+
+	folder, err := api.store.CreateFolder("Algèbre")
+	if err != nil {
+		t.Fatalf("create folder: %v", err)
+	}
+	docs := make([]domain.Document, 2)
+	for i := range docs {
+		docs[i], err = api.store.CreateDocument(domain.Document{FolderID: folder.ID, Title: fmt.Sprintf("Cours %d", i+1)})
+		if err != nil {
+			t.Fatalf("create document: %v", err)
+		}
+		if _, err := api.conversations.Append(domain.ConversationScopeDocument, docs[i].ID, domain.ChatMessage{Role: domain.ChatRoleUser, Content: "Question ?"}); err != nil {
+			t.Fatalf("append conversation: %v", err)
+		}
+		if _, err := api.quizzes.CreateQuiz(domain.Quiz{DocumentID: docs[i].ID, Title: "Quiz"}); err != nil {
+			t.Fatalf("create quiz: %v", err)
+		}
+		if _, err := api.flashcards.AddCards(docs[i].ID, []domain.Flashcard{{Front: "Recto", Back: "Verso"}}, false); err != nil {
+			t.Fatalf("add flashcards: %v", err)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	engine.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/api/documents/"+docs[0].ID, nil))
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("expected 204 for document deletion, got %d", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	engine.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/api/folders/"+folder.ID, nil))
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("expected 204 for folder deletion, got %d", rec.Code)
+	}
+
+	for _, doc := range docs {
+		conversation, err := api.conversations.Get(domain.ConversationScopeDocument, doc.ID)
+		if err != nil {
+			t.Fatalf("get conversation: %v", err)
+		}
+		if len(conversation.Messages) != 0 {
+			t.Fatalf("expected conversation of %s to be deleted, got %d messages", doc.Title, len(conversation.Messages))
+		}
+		if quizzes := api.quizzes.ListQuizzesByDocument(doc.ID); len(quizzes) != 0 {
+			t.Fatalf("expected quizzes of %s to be deleted, got %d", doc.Title, len(quizzes))
+		}
+		if cards := api.flashcards.ListByDocument(doc.ID); len(cards) != 0 {
+			t.Fatalf("expected flashcards of %s to be deleted, got %d", doc.Title, len(cards))
+		}
+	}
+}
+
+func TestCORSAllowsPut(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.Use(CORS())
+
+	req := httptest.NewRequest(http.MethodOptions, "/api/folders/f1/pdf-template", nil)
+	req.Header.Set("Origin", "http://localhost:5173")
+	req.Header.Set("Access-Control-Request-Method", http.MethodPut)
+	rec := httptest.NewRecorder()
+	engine.ServeHTTP(rec, req)
+
+	if !strings.Contains(rec.Header().Get("Access-Control-Allow-Methods"), http.MethodPut) {
+		t.Fatalf("expected PUT in preflight response, got %q (status %d)", rec.Header().Get("Access-Control-Allow-Methods"), rec.Code)
+	}
+}
+
+func TestRecoverInterruptedWorkReconcilesStuckDocuments(t *testing.T) {
+	api := newTestAPI(t, &fakeProvider{})
+
+	withAudio, err := api.store.CreateDocument(domain.Document{
+		Title:            "Avec audio",
+		AudioPath:        "audio/lecture.mp3",
+		ProcessingStatus: domain.ProcessingStatusProcessing,
+	})
+	if err != nil {
+		t.Fatalf("create document: %v", err)
+	}
+	withoutAudio, err := api.store.CreateDocument(domain.Document{
+		Title:            "Sans audio",
+		ProcessingStatus: domain.ProcessingStatusProcessing,
+	})
+	if err != nil {
+		t.Fatalf("create document: %v", err)
+	}
+
+	api.reconcileOrphans(time.Now().Add(-time.Minute))
+	if doc, _ := api.store.GetDocument(withoutAudio.ID); doc.ProcessingStatus != domain.ProcessingStatusProcessing {
+		t.Fatalf("expected recently updated document to be left alone, got %q", doc.ProcessingStatus)
+	}
+
+	api.recoverInterruptedWork()
+
+	requeued, err := api.store.GetDocument(withAudio.ID)
+	if err != nil {
+		t.Fatalf("get document: %v", err)
+	}
+	if requeued.ProcessingStatus != domain.ProcessingStatusPending || requeued.ProcessingError != "" {
+		t.Fatalf("expected re-queued document to be pending, got %q (%q)", requeued.ProcessingStatus, requeued.ProcessingError)
+	}
+	job, active := api.jobs.ActiveJob(withAudio.ID)
+	if !active || job.Type != domain.JobTypeTranscribe {
+		t.Fatalf("expected an active transcribe job, got %+v (active=%v)", job, active)
+	}
+
+	failed, err := api.store.GetDocument(withoutAudio.ID)
+	if err != nil {
+		t.Fatalf("get document: %v", err)
+	}
+	if failed.ProcessingStatus != domain.ProcessingStatusFailed {
+		t.Fatalf("expected failed status, got %q", failed.ProcessingStatus)
+	}
+	if failed.ProcessingError != errProcessingInterrupted.Error() {
+		t.Fatalf("unexpected processing error %q", failed.ProcessingError)
+	}
+}
+
+func TestGenerateCourseUsesProvider(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	engine, store := setupTestServer(t)
+
+	doc, err := store.CreateDocument(domain.Document{
+		Title:         "Lecture",
+		Transcription: "bonjour à tous",
+	})
+	if err != nil {
+		t.Fatalf("create document: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/documents/"+doc.ID+"/course", nil)
+	rec := httptest.NewRecorder()
+
+	engine.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+
+	updated, err := store.GetDocument(doc.ID)
+	if err != nil {
+		t.Fatalf("get document: %v", err)
+	}
+	if updated.Course != "# Cours de test" {
+		t.Fatalf("expected course from provider, got %q", updated.Course)
+	}
+}
+
+func TestStreamCourse(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	engine, store := setupTestServer(t)
+
+	doc, err := store.CreateDocument(domain.Document{
+		Title:         "Lecture",
+		Transcription: "bonjour à tous",
+	})
+	if err != nil {
+		t.Fatalf("create document: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/documents/"+doc.ID+"/course/stream", nil)
+	rec := httptest.NewRecorder()
+
+	engine.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	body := rec.Body.String()
+	if strings.Count(body, "event:delta") != 4 {
+		t.Fatalf("expected 4 delta events, got body %q", body)
+	}
+	if !strings.Contains(body, "event:done") {
+		t.Fatalf("expected done event, got body %q", body)
+	}
+
+	updated, err := store.GetDocument(doc.ID)
+	if err != nil {
+		t.Fatalf("get document: %v", err)
+	}
+	if updated.Course != "# Cours de test" {
+		t.Fatalf("expected streamed course to be stored, got %q", updated.Course)
+	}
+}
+
+func TestSearchDocuments(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	engine, store := setupTestServer(t)
+
+	folder, err := store.CreateFolder("Algèbre")
+	if err != nil {
+		t.Fatalf("create folder: %v", err)
+	}
+	doc, err := store.CreateDocument(domain.Document{FolderID: folder.ID, Title: "Cours 3", Transcription: "On calcule les valeurs propres."})
+	if err != nil {
+		t.Fatalf("create document: %v", err)
+	}
+	doc.Summary = "Résumé sur les matrices diagonalisables"
+	if _, err := store.UpdateDocument(doc); err != nil {
+		t.Fatalf("update document: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/search?q=diagonalisable&folderId="+folder.ID, nil)
+	rec := httptest.NewRecorder()
+	engine.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+
+	var body struct {
+		Results []struct {
+			DocumentID string `json:"documentId"`
+			Snippets   []struct {
+				Field string `json:"field"`
+			} `json:"snippets"`
+		} `json:"results"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(body.Results) != 1 || body.Results[0].DocumentID != doc.ID || body.Results[0].Snippets[0].Field != "summary" {
+		t.Fatalf("unexpected search results %s", rec.Body.String())
+	}
+
+	if err := store.DeleteDocument(doc.ID); err != nil {
+		t.Fatalf("delete document: %v", err)
+	}
+	rec = httptest.NewRecorder()
+	engine.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/search?q=diagonalisable", nil))
+	if strings.Contains(rec.Body.String(), doc.ID) {
+		t.Fatalf("expected deleted document to leave the index, got %s", rec.Body.String())
+	}
+}
+
+func TestRelatedDocuments(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	engine, store := setupTestServer(t)
+
+	algebra, _ := store.CreateDocument(domain.Document{Title: "Algèbre", Transcription: "matrice valeurs propres vecteurs propres"})
+	algebra2, _ := store.CreateDocument(domain.Document{Title: "Algèbre 2", Transcription: "vecteurs propres matrice diagonalisation"})
+	biology, _ := store.CreateDocument(domain.Document{Title: "Biologie", Transcription: "photosynthèse chlorophylle plantes"})
+
+	var body struct {
+		Results []struct {
+			DocumentID string `json:"documentId"`
+			Title      string `json:"title"`
+		} `json:"results"`
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		rec := httptest.NewRecorder()
+		engine.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/documents/"+algebra.ID+"/related", nil))
+		if rec.Code == http.StatusOK {
+			if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+				t.Fatalf("decode response: %v", err)
+			}
+			if len(body.Results) == 2 {
+				break
+			}
+		} else if rec.Code != http.StatusConflict {
+			t.Fatalf("expected 200 or 409 while indexing, got %d", rec.Code)
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("related documents not available in time")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if body.Results[0].DocumentID != algebra2.ID || body.Results[0].Title != "Algèbre 2" || body.Results[1].DocumentID != biology.ID {
+		t.Fatalf("unexpected related order %+v", body.Results)
+	}
+
+	payload := strings.NewReader(`{"field":"transcription","content":"photosynthèse des plantes vertes"}`)
+	req := httptest.NewRequest(http.MethodPatch, "/api/documents/"+algebra.ID+"/content", payload)
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	engine.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200 for content update, got %d", rec.Code)
+	}
+
+	deadline = time.Now().Add(5 * time.Second)
+	for {
+		rec := httptest.NewRecorder()
+		engine.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/documents/"+algebra.ID+"/related?limit=1", nil))
+		if rec.Code == http.StatusOK {
+			_ = json.Unmarshal(rec.Body.Bytes(), &body)
+			if len(body.Results) == 1 && body.Results[0].DocumentID == biology.ID {
+				break
+			}
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("vectors were not refreshed after the transcription edit, last body %s", rec.Body.String())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestDocumentChatCitesPassagesAndKeepsHistory(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	engine, store := setupTestServer(t)
+
+	doc, err := store.CreateDocument(domain.Document{
+		Title:         "Amphi 4",
+		Transcription: "L'examen sera un QCM de vingt questions.",
+		Segments:      []domain.Segment{{Start: 65, End: 71.5, Text: "L'examen sera un QCM de vingt questions."}},
+	})
+	if err != nil {
+		t.Fatalf("create document: %v", err)
+	}
+
+	ask := func(question string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/documents/"+doc.ID+"/chat", strings.NewReader(`{"question":"`+question+`"}`))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		engine.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := ask("Quel est le format de l'examen ?")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var body struct {
+		Answer    string            `json:"answer"`
+		Citations []domain.Citation `json:"citations"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(body.Citations) != 1 || body.Citations[0].DocumentID != doc.ID {
+		t.Fatalf("expected one citation of the document, got %+v", body.Citations)
+	}
+	if body.Citations[0].Start == nil || *body.Citations[0].Start != 65 {
+		t.Fatalf("expected citation timestamp from segments, got %+v", body.Citations[0])
+	}
+
+	if rec := ask("Combien de questions ?"); rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "n°2") {
+		t.Fatalf("expected follow-up to see the history, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	engine.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/documents/"+doc.ID+"/chat", nil))
+	var conversation domain.Conversation
+	if err := json.Unmarshal(rec.Body.Bytes(), &conversation); err != nil {
+		t.Fatalf("decode conversation: %v", err)
+	}
+	if len(conversation.Messages) != 4 || conversation.Messages[0].Role != domain.ChatRoleUser {
+		t.Fatalf("expected persisted history of 4 messages, got %+v", conversation.Messages)
+	}
+
+	rec = ask("Qui a gagné la coupe du monde ?")
+	body.Citations = nil
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if body.Answer != "Je ne sais pas." || len(body.Citations) != 0 {
+		t.Fatalf("expected no passages for an unrelated question, got %s", rec.Body.String())
+	}
+
+	if rec := ask(""); rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for an empty question, got %d", rec.Code)
+	}
+}
+
+func TestQuizGenerationAndGrading(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	engine, store := setupTestServer(t)
+
+	doc, err := store.CreateDocument(domain.Document{Title: "Amphi 5", Transcription: "Le mot clé est transcription."})
+	if err != nil {
+		t.Fatalf("create document: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/documents/"+doc.ID+"/quiz", strings.NewReader(`{"count":2}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	engine.ServeHTTP(rec, req)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var quiz domain.Quiz
+	if err := json.Unmarshal(rec.Body.Bytes(), &quiz); err != nil {
+		t.Fatalf("decode quiz: %v", err)
+	}
+	if quiz.DocumentID != doc.ID || len(quiz.Questions) != 2 {
+		t.Fatalf("unexpected quiz: %+v", quiz)
+	}
+
+	rec = httptest.NewRecorder()
+	engine.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/quizzes/"+quiz.ID, nil))
+	if rec.Code != http.StatusOK || strings.Contains(rec.Body.String(), "answerIndex") {
+		t.Fatalf("expected quiz without answers, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	submit := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/quizzes/"+quiz.ID+"/attempts", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		engine.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec = submit(`{"answers":[{"questionId":"q1","choice":1},{"questionId":"q2","text":"Transcription"}]}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var attempt domain.QuizAttempt
+	if err := json.Unmarshal(rec.Body.Bytes(), &attempt); err != nil {
+		t.Fatalf("decode attempt: %v", err)
+	}
+	if attempt.Score != 2 || attempt.MaxScore != 2 || !attempt.Answers[0].Correct {
+		t.Fatalf("expected a perfect score, got %+v", attempt)
+	}
+
+	rec = submit(`{"answers":[{"questionId":"q1","choice":0}]}`)
+	if err := json.Unmarshal(rec.Body.Bytes(), &attempt); err != nil {
+		t.Fatalf("decode attempt: %v", err)
+	}
+	if attempt.Score != 0 || attempt.Answers[0].Correct || attempt.Answers[0].Feedback != "B est correct." {
+		t.Fatalf("expected a wrong choice with explanation, got %+v", attempt)
+	}
+
+	if rec := submit(`{"answers":[{"questionId":"q9","choice":0}]}`); rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for an unknown question, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	engine.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/quizzes/"+quiz.ID+"/attempts", nil))
+	var attempts []domain.QuizAttempt
+	if err := json.Unmarshal(rec.Body.Bytes(), &attempts); err != nil {
+		t.Fatalf("decode attempts: %v", err)
+	}
+	if len(attempts) != 2 {
+		t.Fatalf("expected 2 stored attempts, got %d", len(attempts))
+	}
+
+	rec = httptest.NewRecorder()
+	engine.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/api/documents/"+doc.ID, nil))
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("expected 204 for document deletion, got %d", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	engine.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/quizzes/"+quiz.ID, nil))
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected quiz to be deleted with its document, got %d", rec.Code)
+	}
+}
+
+func TestQuizGenerationRetriesAndHonoursCount(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	provider := &fakeProvider{quizFailures: 1}
+	engine, store := setupTestServerWithProvider(t, provider)
+
+	doc, err := store.CreateDocument(domain.Document{Title: "Amphi 6", Transcription: "Le mot clé est transcription."})
+	if err != nil {
+		t.Fatalf("create document: %v", err)
+	}
+
+	generate := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/documents/"+doc.ID+"/quiz", strings.NewReader(`{"count":1}`))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		engine.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := generate()
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201 after a transient provider error, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var quiz domain.Quiz
+	if err := json.Unmarshal(rec.Body.Bytes(), &quiz); err != nil {
+		t.Fatalf("decode quiz: %v", err)
+	}
+	if len(quiz.Questions) != 1 {
+		t.Fatalf("expected the requested single question, got %d", len(quiz.Questions))
+	}
+
+	provider.quizFailures = quizGenerationTries
+	if rec := generate(); rec.Code != http.StatusInternalServerError {
+		t.Fatalf("expected 500 once every attempt failed, got %d", rec.Code)
+	}
+}
+
+func TestFlashcardReviewSchedule(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	engine, store := setupTestServer(t)
+
+	doc, err := store.CreateDocument(domain.Document{Title: "Algèbre", Summary: "Valeurs propres et trace."})
+	if err != nil {
+		t.Fatalf("create document: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/documents/"+doc.ID+"/flashcards", strings.NewReader(`{"source":"course"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	engine.ServeHTTP(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 without a course, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	engine.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/documents/"+doc.ID+"/flashcards", nil))
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var cards []domain.Flashcard
+	if err := json.Unmarshal(rec.Body.Bytes(), &cards); err != nil {
+		t.Fatalf("decode flashcards: %v", err)
+	}
+	if len(cards) != 2 || cards[0].Source != domain.FlashcardSourceSummary {
+		t.Fatalf("expected 2 cards from the summary, got %+v", cards)
+	}
+
+	dueCount := func() int {
+		rec := httptest.NewRecorder()
+		engine.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/reviews/due?documentId="+doc.ID, nil))
+		var due []domain.Flashcard
+		if err := json.Unmarshal(rec.Body.Bytes(), &due); err != nil {
+			t.Fatalf("decode due cards: %v", err)
+		}
+		return len(due)
+	}
+	if n := dueCount(); n != 2 {
+		t.Fatalf("expected new cards to be due, got %d", n)
+	}
+
+	reviewCard := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/flashcards/"+cards[0].ID+"/review", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		engine.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := reviewCard(`{"grade":7}`); rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for an invalid grade, got %d", rec.Code)
+	}
+
+	rec = reviewCard(`{"grade":5}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var reviewed domain.Flashcard
+	if err := json.Unmarshal(rec.Body.Bytes(), &reviewed); err != nil {
+		t.Fatalf("decode flashcard: %v", err)
+	}
+	if reviewed.Interval != 1 || reviewed.Repetitions != 1 || reviewed.EaseFactor != 2.6 || reviewed.DueAt <= time.Now().Unix() {
+		t.Fatalf("unexpected schedule after review: %+v", reviewed)
+	}
+	if n := dueCount(); n != 1 {
+		t.Fatalf("expected the reviewed card to leave the due list, got %d due", n)
+	}
+}
+
+func TestExportFolderToAnki(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	engine, store := setupTestServer(t)
+
+	folder, err := store.CreateFolder("Algèbre linéaire")
+	if err != nil {
+		t.Fatalf("create folder: %v", err)
+	}
+
+	rec := httptest.NewRecorder()
+	engine.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/folders/"+folder.ID+"/export/anki", nil))
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("expected 409 for a folder without flashcards, got %d", rec.Code)
+	}
+
+	for _, title := range []string{"Amphi 1", "Amphi 2"} {
+		if _, err := store.CreateDocument(domain.Document{FolderID: folder.ID, Title: title, Course: "# Cours"}); err != nil {
+			t.Fatalf("create document: %v", err)
+		}
+	}
+
+	rec = httptest.NewRecorder()
+	engine.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/folders/"+folder.ID+"/export/anki?generate=missing", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if !strings.Contains(rec.Header().Get("Content-Disposition"), ".apkg") {
+		t.Fatalf("unexpected content disposition %q", rec.Header().Get("Content-Disposition"))
+	}
+
+	archive, err := zip.NewReader(bytes.NewReader(rec.Body.Bytes()), int64(rec.Body.Len()))
+	if err != nil {
+		t.Fatalf("open apkg: %v", err)
+	}
+	collection := filepath.Join(t.TempDir(), "collection.anki2")
+	for _, file := range archive.File {
+		if file.Name != "collection.anki2" {
+			continue
+		}
+		rc, err := file.Open()
+		if err != nil {
+			t.Fatalf("open collection: %v", err)
+		}
+		data, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatalf("read collection: %v", err)
+		}
+		if err := os.WriteFile(collection, data, 0o644); err != nil {
+			t.Fatalf("write collection: %v", err)
+		}
+	}
+
+	db, err := sql.Open("sqlite", "file:"+collection+"?mode=ro")
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	defer db.Close()
+
+	var notes, decks int
+	if err := db.QueryRow(`SELECT count(*), count(DISTINCT c.did) FROM notes n JOIN cards c ON c.nid = n.id`).Scan(&notes, &decks); err != nil {
+		t.Fatalf("count notes: %v", err)
+	}
+	if notes != 4 || decks != 1 {
+		t.Fatalf("expected 4 notes in one deck, got %d notes in %d decks", notes, decks)
+	}
+	var tagged int
+	if err := db.QueryRow(`SELECT count(*) FROM notes WHERE tags = ' Amphi_2 '`).Scan(&tagged); err != nil {
+		t.Fatalf("count tags: %v", err)
+	}
+	if tagged != 2 {
+		t.Fatalf("expected 2 notes tagged with the document title, got %d", tagged)
+	}
+}
+
+func TestGeneratePDFSections(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	engine, store := setupTestServer(t)
+
+	doc, err := store.CreateDocument(domain.Document{Title: "Amphi 6", Course: "# Titre\n\n- point **clé**", Summary: "résumé"})
+	if err != nil {
+		t.Fatalf("create document: %v", err)
+	}
+
+	generate := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/documents/"+doc.ID+"/pdf", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		engine.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := generate(`{"sections":["course","quiz"]}`); rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for an unknown section, got %d", rec.Code)
+	}
+
+	rec := generate(`{"sections":["course"]}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	updated, err := store.GetDocument(doc.ID)
+	if err != nil {
+		t.Fatalf("get document: %v", err)
+	}
+	if _, err := os.Stat(updated.PDFPath); err != nil {
+		t.Fatalf("expected pdf on disk: %v", err)
+	}
+}
+
+func TestPDFTemplateSelection(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	engine, store := setupTestServer(t)
+
+	folder, err := store.CreateFolder("Physique")
+	if err != nil {
+		t.Fatalf("create folder: %v", err)
+	}
+	doc, err := store.CreateDocument(domain.Document{Title: "Amphi 7", FolderID: folder.ID, Course: "# Ondes"})
+	if err != nil {
+		t.Fatalf("create document: %v", err)
+	}
+
+	send := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		engine.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := send(http.MethodGet, "/api/pdf-templates", "")
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"name":"default"`) {
+		t.Fatalf("expected the default template to be listed, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	if rec := send(http.MethodPut, "/api/folders/"+folder.ID+"/pdf-template", `{"template":"absent"}`); rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for an unknown folder template, got %d", rec.Code)
+	}
+	if rec := send(http.MethodPut, "/api/folders/missing/pdf-template", `{"template":"default"}`); rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for an unknown folder, got %d", rec.Code)
+	}
+	if rec := send(http.MethodPut, "/api/folders/"+folder.ID+"/pdf-template", `{"template":"default"}`); rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	updated, err := store.GetFolder(folder.ID)
+	if err != nil || updated.PDFTemplate != "default" {
+		t.Fatalf("expected the folder default to be stored, got %+v (%v)", updated, err)
+	}
+
+	if rec := send(http.MethodPost, "/api/documents/"+doc.ID+"/pdf", `{"template":"absent"}`); rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for an unknown template, got %d", rec.Code)
+	}
+	if rec := send(http.MethodPost, "/api/documents/"+doc.ID+"/pdf", `{"template":"default"}`); rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestFolderBinderPDF(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	engine, store := setupTestServer(t)
+
+	folder, err := store.CreateFolder("Analyse")
+	if err != nil {
+		t.Fatalf("create folder: %v", err)
+	}
+	ids := make([]string, 0, 3)
+	for i, title := range []string{"Amphi 1", "Amphi 2", "Amphi 3"} {
+		doc, err := store.CreateDocument(domain.Document{Title: title, FolderID: folder.ID, CreatedAt: int64(10 - i), Course: "# " + title})
+		if err != nil {
+			t.Fatalf("create document: %v", err)
+		}
+		ids = append(ids, doc.ID)
+	}
+	empty, err := store.CreateFolder("Vide")
+	if err != nil {
+		t.Fatalf("create folder: %v", err)
+	}
+
+	send := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		engine.ServeHTTP(rec, req)
+		return rec
+	}
+	generate := func(body string) (int, []string) {
+		rec := send(http.MethodPost, "/api/folders/"+folder.ID+"/pdf", body)
+		var payload struct {
+			DocumentIDs []string `json:"documentIds"`
+		}
+		_ = json.Unmarshal(rec.Body.Bytes(), &payload)
+		return rec.Code, payload.DocumentIDs
+	}
+
+	if rec := send(http.MethodPost, "/api/folders/"+folder.ID+"/share", ""); rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 before the binder exists, got %d", rec.Code)
+	}
+
+	code, order := generate("")
+	if code != http.StatusOK || strings.Join(order, ",") != strings.Join([]string{ids[2], ids[1], ids[0]}, ",") {
+		t.Fatalf("expected documents ordered by creation date, got %d %v", code, order)
+	}
+	code, order = generate(`{"order":["` + ids[1] + `"]}`)
+	if code != http.StatusOK || strings.Join(order, ",") != strings.Join([]string{ids[1], ids[2], ids[0]}, ",") {
+		t.Fatalf("expected the requested order first, got %d %v", code, order)
+	}
+	if code, _ := generate(`{"order":["` + ids[0] + `","` + ids[0] + `"]}`); code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for a duplicated document, got %d", code)
+	}
+	if code, _ := generate(`{"order":["ailleurs"]}`); code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for a document outside the folder, got %d", code)
+	}
+	if code, _ := generate(`{"template":"absent"}`); code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for an unknown template, got %d", code)
+	}
+	if rec := send(http.MethodPost, "/api/folders/"+empty.ID+"/pdf", ""); rec.Code != http.StatusConflict {
+		t.Fatalf("expected 409 for an empty folder, got %d", rec.Code)
+	}
+	if rec := send(http.MethodPost, "/api/folders/missing/pdf", ""); rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for an unknown folder, got %d", rec.Code)
+	}
+
+	rec := send(http.MethodPost, "/api/folders/"+folder.ID+"/share", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var shared struct {
+		URL string `json:"url"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &shared); err != nil {
+		t.Fatalf("decode share response: %v", err)
+	}
+
+	download := send(http.MethodGet, strings.TrimPrefix(shared.URL, "http://localhost:8080"), "")
+	if download.Code != http.StatusOK || !strings.HasPrefix(download.Body.String(), "%PDF-") {
+		t.Fatalf("expected the binder through the signed url, got %d", download.Code)
+	}
+	if rec := send(http.MethodGet, "/pdf/folders/"+folder.ID+"?exp=9999999999&sig=invalid", ""); rec.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 for an invalid signature, got %d", rec.Code)
+	}
+}
+
+func TestExportSubtitles(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	engine, store := setupTestServer(t)
+
+	untimed, err := store.CreateDocument(domain.Document{Title: "Sans minutage", Transcription: "texte"})
+	if err != nil {
+		t.Fatalf("create document: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/documents/"+untimed.ID+"/export?format=srt", nil)
+	rec := httptest.NewRecorder()
+	engine.ServeHTTP(rec, req)
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("expected 409 without segments, got %d", rec.Code)
+	}
+
+	doc, err := store.CreateDocument(domain.Document{
+		Title:         "Cours",
+		Transcription: "Bonjour à tous. Aujourd'hui nous allons étudier les valeurs propres d'une matrice carrée et leurs applications.",
+		Segments: []domain.Segment{
+			{Start: 0, End: 1.5, Text: "Bonjour à tous."},
+			{Start: 3661.25, End: 3670, Text: "Aujourd'hui nous allons étudier les valeurs propres d'une matrice carrée et leurs applications."},
+		},
+	})
+	if err != nil {
+		t.Fatalf("create document: %v", err)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/documents/"+doc.ID+"/export?format=srt", nil)
+	rec = httptest.NewRecorder()
+	engine.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+
+	wantSRT := "1\n00:00:00,000 --> 00:00:01,500\nBonjour à tous.\n\n" +
+		"2\n01:01:01,250 --> 01:01:08,212\nAujourd'hui nous allons étudier les\nvaleurs propres d'une matrice carrée et\n\n" +
+		"3\n01:01:08,212 --> 01:01:10,000\nleurs applications.\n\n"
+	if rec.Body.String() != wantSRT {
+		t.Fatalf("unexpected srt output:\n got: %q\nwant: %q", rec.Body.String(), wantSRT)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/documents/"+doc.ID+"/export?format=vtt", nil)
+	rec = httptest.NewRecorder()
+	engine.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	if !strings.HasPrefix(rec.Body.String(), "WEBVTT\n\n1\n00:00:00.000 --> 00:00:01.500\n") {
+		t.Fatalf("unexpected vtt output: %q", rec.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodPatch, "/api/documents/"+doc.ID+"/content", strings.NewReader(`{"field":"transcription","content":"Texte corrigé à la main."}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec = httptest.NewRecorder()
+	engine.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200 for content update, got %d", rec.Code)
+	}
+
+	edited, err := store.GetDocument(doc.ID)
+	if err != nil {
+		t.Fatalf("get document: %v", err)
+	}
+	if len(edited.Segments) != 0 {
+		t.Fatalf("expected segments to be cleared after editing the transcription, got %d", len(edited.Segments))
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/documents/"+doc.ID+"/export?format=srt", nil)
+	rec = httptest.NewRecorder()
+	engine.ServeHTTP(rec, req)
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("expected 409 after editing the transcription, got %d", rec.Code)
+	}
+}
+
+func TestGenerateSummaryKeepsConcurrentEdits(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	provider := &fakeProvider{summary: "résumé de test"}
+	engine, store := setupTestServerWithProvider(t, provider)
+
+	doc, err := store.CreateDocument(domain.Document{Title: "Lecture", Transcription: "bonjour à tous"})
+	if err != nil {
+		t.Fatalf("create document: %v", err)
+	}
+	provider.onSummarize = func() {
+		edited, err := store.GetDocument(doc.ID)
+		if err != nil {
+			t.Errorf("get document: %v", err)
+			return
+		}
+		edited.Title = "Lecture renommée"
+		edited.Course = "# Cours corrigé"
+		if _, err := store.UpdateDocument(edited); err != nil {
+			t.Errorf("update document: %v", err)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	engine.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/documents/"+doc.ID+"/summary", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+
+	updated, err := store.GetDocument(doc.ID)
+	if err != nil {
+		t.Fatalf("get document: %v", err)
+	}
+	if updated.Summary != "résumé de test" || updated.Title != "Lecture renommée" || updated.Course != "# Cours corrigé" {
+		t.Fatalf("expected the summary to be merged with concurrent edits, got %+v", updated)
+	}
+}
+
+func TestGenerateSummary(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	engine, store := setupTestServer(t)
+
+	empty, err := store.CreateDocument(domain.Document{Title: "Vide"})
+	if err != nil {
+		t.Fatalf("create document: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/documents/"+empty.ID+"/summary", nil)
+	rec := httptest.NewRecorder()
+	engine.ServeHTTP(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 without transcription, got %d", rec.Code)
+	}
+
+	doc, err := store.CreateDocument(domain.Document{Title: "Lecture", Transcription: "bonjour à tous"})
+	if err != nil {
+		t.Fatalf("create document: %v", err)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/api/documents/"+doc.ID+"/summary", strings.NewReader(`{"instructions":"en 3 points"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec = httptest.NewRecorder()
+	engine.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+
+	updated, err := store.GetDocument(doc.ID)
+	if err != nil {
+		t.Fatalf("get document: %v", err)
+	}
+	if updated.Summary != "résumé de test" {
+		t.Fatalf("expected summary from provider, got %q", updated.Summary)
+	}
+}
+
+func TestResumePipelineSkipsCompletedSteps(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	engine, store := setupTestServer(t)
+
+	doc, err := store.CreateDocument(domain.Document{
+		Title:         "Lecture",
+		Transcription: "transcription existante",
+		Pipeline: &domain.Pipeline{
+			Steps:      []string{domain.PipelineStepTranscribe, domain.PipelineStepSummarize, domain.PipelineStepCourse},
+			Completed:  []string{domain.PipelineStepTranscribe},
+			FailedStep: domain.PipelineStepSummarize,
+			Status:     domain.ProcessingStatusFailed,
+		},
+	})
+	if err != nil {
+		t.Fatalf("create document: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/documents/"+doc.ID+"/pipeline/resume", nil)
+	rec := httptest.NewRecorder()
+	engine.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d", rec.Code)
+	}
+
+	var body struct {
+		Job domain.Job `json:"job"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+
+	if job := waitForJob(t, engine, body.Job.ID); job.Status != domain.ProcessingStatusCompleted {
+		t.Fatalf("expected completed pipeline job, got %q (%s)", job.Status, job.Error)
+	}
+
+	updated, err := store.GetDocument(doc.ID)
+	if err != nil {
+		t.Fatalf("get document: %v", err)
+	}
+	if updated.Transcription != "transcription existante" {
+		t.Fatalf("transcription step should not run again, got %q", updated.Transcription)
+	}
+	if updated.Summary != "résumé de test" || updated.Course != "# Cours de test" {
+		t.Fatalf("expected summary and course, got %q %q", updated.Summary, updated.Course)
+	}
+	if updated.Pipeline.Status != domain.ProcessingStatusCompleted || len(updated.Pipeline.Completed) != 3 {
+		t.Fatalf("unexpected pipeline state: %+v", updated.Pipeline)
+	}
+}
+
+func TestDocumentEventsStream(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	engine, store := setupTestServer(t)
+
+	srv := httptest.NewServer(engine)
+	defer srv.Close()
+
+	doc, err := store.CreateDocument(domain.Document{Title: "Lecture"})
+	if err != nil {
+		t.Fatalf("create document: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/api/documents/"+doc.ID+"/events", nil)
+	if err != nil {
+		t.Fatalf("new request: %v", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/event-stream") {
+		t.Fatalf("expected event stream, got %q", ct)
+	}
+
+	reader := bufio.NewReader(resp.Body)
+	readEvent := func() string {
+		t.Helper()
+		name := ""
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				t.Fatalf("read event: %v", err)
+			}
+			line = strings.TrimRight(line, "\n")
+			if strings.HasPrefix(line, "event:") {
+				name = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+			}
+			if line == "" && name != "" {
+				return name
+			}
+		}
+	}
+
+	if name := readEvent(); name != "snapshot" {
+		t.Fatalf("expected snapshot event, got %q", name)
+	}
+
+	doc.ProcessingStatus = domain.ProcessingStatusProcessing
+	doc.ChunksTotal = 3
+	doc.ChunksDone = 1
+	if _, err := store.UpdateDocument(doc); err != nil {
+		t.Fatalf("update document: %v", err)
+	}
+
+	if name := readEvent(); name != "status" {
+		t.Fatalf("expected status event, got %q", name)
+	}
+	if name := readEvent(); name != "progress" {
+		t.Fatalf("expected progress event, got %q", name)
+	}
+
+	if err := store.DeleteDocument(doc.ID); err != nil {
+		t.Fatalf("delete document: %v", err)
+	}
+	if name := readEvent(); name != "deleted" {
+		t.Fatalf("expected deleted event, got %q", name)
+	}
+}
@@ -0,0 +1,54 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+)
+
+func (a *API) handleSearch(c *gin.Context) {
+	query := strings.TrimSpace(c.Query("q"))
+	if query == "" {
+		respondMessage(c, http.StatusBadRequest, "missing query parameter q")
+		return
+	}
+
+	folderID := strings.TrimSpace(c.Query("folderId"))
+	if folderID != "" {
+		if _, err := a.store.GetFolder(folderID); err != nil {
+			respondMessage(c, http.StatusNotFound, "folder not found")
+			return
+		}
+	}
+
+	limit, ok := parseLimit(c, defaultSearchLimit)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"query":   query,
+		"results": a.search.Search(query, folderID, limit),
+	})
+}
+
+func parseLimit(c *gin.Context, fallback int) (int, bool) {
+	raw := strings.TrimSpace(c.Query("limit"))
+	if raw == "" {
+		return fallback, true
+	}
+
+	n, err := strconv.Atoi(raw)
+	if err != nil || n <= 0 {
+		respondMessage(c, http.StatusBadRequest, "invalid limit")
+		return 0, false
+	}
+	return min(n, maxSearchLimit), true
+}
@@ -0,0 +1,101 @@
+package http
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"myProfessor/internal/semantic"
+)
+
+const defaultRelatedLimit = 5
+
+type semanticResult struct {
+	semantic.Match
+	FolderID string `json:"folderId"`
+	Title    string `json:"title"`
+}
+
+func (a *API) handleSemanticSearch(c *gin.Context) {
+	if a.semantic == nil {
+		respondMessage(c, http.StatusServiceUnavailable, "semantic search is disabled")
+		return
+	}
+
+	query := strings.TrimSpace(c.Query("q"))
+	if query == "" {
+		respondMessage(c, http.StatusBadRequest, "missing query parameter q")
+		return
+	}
+
+	limit, ok := parseLimit(c, defaultSearchLimit)
+	if !ok {
+		return
+	}
+
+	var allow func(string) bool
+	if folderID := strings.TrimSpace(c.Query("folderId")); folderID != "" {
+		folder, err := a.store.GetFolder(folderID)
+		if err != nil {
+			respondMessage(c, http.StatusNotFound, "folder not found")
+			return
+		}
+		inFolder := make(map[string]bool, len(folder.DocumentIDs))
+		for _, id := range folder.DocumentIDs {
+			inFolder[id] = true
+		}
+		allow = func(id string) bool { return inFolder[id] }
+	}
+
+	matches, err := a.semantic.SearchDocuments(c.Request.Context(), query, allow, limit)
+	if err != nil {
+		log.Printf("semantic search failed: %v", err)
+		respondError(c, http.StatusBadGateway, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"query": query, "results": a.semanticResults(matches)})
+}
+
+func (a *API) handleRelatedDocuments(c *gin.Context) {
+	doc, ok := a.documentOr404(c)
+	if !ok {
+		return
+	}
+	if a.semantic == nil {
+		respondMessage(c, http.StatusServiceUnavailable, "semantic search is disabled")
+		return
+	}
+
+	limit, ok := parseLimit(c, defaultRelatedLimit)
+	if !ok {
+		return
+	}
+
+	matches, err := a.semantic.Related(doc.ID, limit)
+	if errors.Is(err, semantic.ErrNotIndexed) {
+		respondMessage(c, http.StatusConflict, err.Error())
+		return
+	}
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"documentId": doc.ID, "results": a.semanticResults(matches)})
+}
+
+func (a *API) semanticResults(matches []semantic.Match) []semanticResult {
+	results := make([]semanticResult, 0, len(matches))
+	for _, match := range matches {
+		doc, err := a.store.GetDocument(match.DocumentID)
+		if err != nil {
+			continue
+		}
+		results = append(results, semanticResult{Match: match, FolderID: doc.FolderID, Title: doc.Title})
+	}
+	return results
+}
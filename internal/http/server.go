@@ -1,18 +1,26 @@
 package http
 
 import (
+	"context"
 	"fmt"
+	"path/filepath"
 
 	"github.com/gin-gonic/gin"
 
 	"myProfessor/internal/config"
+	"myProfessor/internal/jobs"
+	"myProfessor/internal/semantic"
 	"myProfessor/internal/services"
 	"myProfessor/internal/storage"
 )
 
 type Server struct {
-	engine *gin.Engine
-	cfg    config.Config
+	engine   *gin.Engine
+	api      *API
+	cfg      config.Config
+	store    storage.Store
+	jobs     *jobs.Queue
+	semantic *semantic.Index
 }
 
 func NewServer(cfg config.Config) (*Server, error) {
@@ -23,27 +31,99 @@ func NewServer(cfg config.Config) (*Server, error) {
 		return nil, fmt.Errorf("init file manager: %w", err)
 	}
 
-	store, err := storage.NewStore(cfg.DataDir)
+	store, err := newStore(cfg)
 	if err != nil {
 		return nil, fmt.Errorf("init store: %w", err)
 	}
 	openaiSvc := services.NewOpenAIService(cfg)
+
+	var transcriber services.Transcriber = openaiSvc
+	if cfg.TranscriptionProvider == config.TranscriptionProviderWhisperCpp {
+		whisperSvc, err := services.NewWhisperCppService(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("init whisper.cpp: %w", err)
+		}
+		transcriber = whisperSvc
+	}
 	pdfSvc := services.NewPDFService()
+	if err := pdfSvc.LoadTemplates(filepath.Join(cfg.DataDir, "templates")); err != nil {
+		return nil, fmt.Errorf("load pdf templates: %w", err)
+	}
 	shareSvc := services.NewShareService(cfg)
 
+	queue, err := jobs.NewQueue(cfg.DataDir, cfg.JobWorkers, cfg.JobRetention)
+	if err != nil {
+		return nil, fmt.Errorf("init job queue: %w", err)
+	}
+
+	var semanticIndex *semantic.Index
+	if cfg.EmbeddingsEnabled {
+		semanticIndex, err = semantic.NewIndex(cfg.DataDir, openaiSvc)
+		if err != nil {
+			return nil, fmt.Errorf("init semantic index: %w", err)
+		}
+	}
+
+	conversations, err := storage.NewConversationStore(cfg.DataDir)
+	if err != nil {
+		return nil, fmt.Errorf("init conversation store: %w", err)
+	}
+
+	quizzes, err := storage.NewQuizStore(cfg.DataDir)
+	if err != nil {
+		return nil, fmt.Errorf("init quiz store: %w", err)
+	}
+
+	flashcards, err := storage.NewFlashcardStore(cfg.DataDir)
+	if err != nil {
+		return nil, fmt.Errorf("init flashcard store: %w", err)
+	}
+
 	engine := gin.New()
 	engine.Use(gin.Recovery())
 	engine.Use(RequestLogger())
 	engine.Use(MaxBodySize(cfg.MaxUploadBytes))
 	engine.Use(CORS())
 
-	api := NewAPI(cfg, fm, store, openaiSvc, pdfSvc, shareSvc)
+	api := NewAPI(cfg, APIDeps{
+		Files:         fm,
+		Store:         store,
+		Transcriber:   transcriber,
+		Generator:     openaiSvc,
+		PDF:           pdfSvc,
+		Share:         shareSvc,
+		Jobs:          queue,
+		Semantic:      semanticIndex,
+		Conversations: conversations,
+		Quizzes:       quizzes,
+		Flashcards:    flashcards,
+	})
 	registerRoutes(engine, api)
+	api.recoverInterruptedWork()
 
-	return &Server{engine: engine, cfg: cfg}, nil
+	return &Server{engine: engine, api: api, cfg: cfg, store: store, jobs: queue, semantic: semanticIndex}, nil
+}
+
+func newStore(cfg config.Config) (storage.Store, error) {
+	if cfg.StoreBackend == config.StoreBackendSQLite {
+		return storage.NewSQLiteStore(cfg.SQLitePath)
+	}
+	return storage.NewJSONStore(cfg.DataDir)
 }
 
 func (s *Server) Run() error {
+	s.jobs.Start()
+	defer s.store.Close()
+	defer s.jobs.Stop()
+	if s.semantic != nil {
+		s.semantic.Start()
+		defer s.semantic.Stop()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go s.api.sweepOrphans(ctx)
+
 	addr := fmt.Sprintf(":%s", s.cfg.Port)
 	return s.engine.Run(addr)
 }
@@ -0,0 +1,454 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"myProfessor/internal/domain"
+)
+
+const (
+	queueCapacity = 256
+	pruneInterval = time.Hour
+)
+
+var (
+	ErrQueueFull   = errors.New("job queue is full")
+	ErrActiveJob   = errors.New("document already has an active job")
+	ErrCancelled   = errors.New("job cancelled")
+	ErrInterrupted = errors.New("job interrupted by a server restart")
+)
+
+type Handler func(ctx context.Context, job domain.Job) error
+
+type Queue struct {
+	mu        sync.RWMutex
+	dir       string
+	workers   int
+	retention time.Duration
+	jobs      map[string]domain.Job
+	handlers  map[string]Handler
+	pending   chan string
+	running   map[string]context.CancelFunc
+	done      map[string]chan struct{}
+	cancel    context.CancelFunc
+	wg        sync.WaitGroup
+}
+
+func NewQueue(baseDir string, workers int, retention time.Duration) (*Queue, error) {
+	dir := filepath.Join(baseDir, "jobs")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create jobs directory: %w", err)
+	}
+
+	if workers < 1 {
+		workers = 1
+	}
+
+	q := &Queue{
+		dir:       dir,
+		workers:   workers,
+		retention: retention,
+		jobs:      map[string]domain.Job{},
+		handlers:  map[string]Handler{},
+		pending:   make(chan string, queueCapacity),
+		running:   map[string]context.CancelFunc{},
+		done:      map[string]chan struct{}{},
+	}
+	if err := q.load(); err != nil {
+		return nil, err
+	}
+	return q, nil
+}
+
+func (q *Queue) Register(jobType string, handler Handler) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.handlers[jobType] = handler
+}
+
+func (q *Queue) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	q.cancel = cancel
+
+	for _, job := range q.List() {
+		if job.Status != domain.ProcessingStatusPending {
+			continue
+		}
+		select {
+		case q.pending <- job.ID:
+		default:
+			q.finish(job.ID, ErrQueueFull)
+		}
+	}
+
+	for i := 0; i < q.workers; i++ {
+		q.wg.Add(1)
+		go q.work(ctx)
+	}
+
+	if q.retention > 0 {
+		q.Prune(q.retention)
+		q.wg.Add(1)
+		go q.pruneLoop(ctx)
+	}
+}
+
+func (q *Queue) Stop() {
+	if q.cancel != nil {
+		q.cancel()
+	}
+	q.wg.Wait()
+}
+
+func (q *Queue) Enqueue(jobType, documentID string) (domain.Job, error) {
+	return q.enqueue(jobType, documentID, false)
+}
+
+func (q *Queue) EnqueueUnique(jobType, documentID string) (domain.Job, error) {
+	return q.enqueue(jobType, documentID, true)
+}
+
+func (q *Queue) enqueue(jobType, documentID string, unique bool) (domain.Job, error) {
+	q.mu.Lock()
+	if _, ok := q.handlers[jobType]; !ok {
+		q.mu.Unlock()
+		return domain.Job{}, fmt.Errorf("unknown job type %s", jobType)
+	}
+	if _, active := q.activeLocked(documentID); unique && active {
+		q.mu.Unlock()
+		return domain.Job{}, ErrActiveJob
+	}
+
+	now := time.Now().Unix()
+	job := domain.Job{
+		ID:         uuid.NewString(),
+		Type:       jobType,
+		DocumentID: documentID,
+		Status:     domain.ProcessingStatusPending,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	if err := q.saveLocked(job); err != nil {
+		q.mu.Unlock()
+		return domain.Job{}, err
+	}
+	q.jobs[job.ID] = job
+	q.mu.Unlock()
+
+	select {
+	case q.pending <- job.ID:
+	default:
+		q.finish(job.ID, ErrQueueFull)
+		return domain.Job{}, ErrQueueFull
+	}
+
+	return job, nil
+}
+
+func (q *Queue) Get(id string) (domain.Job, error) {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	job, ok := q.jobs[id]
+	if !ok {
+		return domain.Job{}, fmt.Errorf("job %s not found", id)
+	}
+	return job, nil
+}
+
+func (q *Queue) List() []domain.Job {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	jobs := make([]domain.Job, 0, len(q.jobs))
+	for _, job := range q.jobs {
+		jobs = append(jobs, job)
+	}
+	return jobs
+}
+
+func (q *Queue) ListByDocument(documentID string) []domain.Job {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	jobs := make([]domain.Job, 0)
+	for _, job := range q.jobs {
+		if job.DocumentID == documentID {
+			jobs = append(jobs, job)
+		}
+	}
+	return jobs
+}
+
+func (q *Queue) ActiveJob(documentID string) (domain.Job, bool) {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	return q.activeLocked(documentID)
+}
+
+func (q *Queue) activeLocked(documentID string) (domain.Job, bool) {
+	for _, job := range q.jobs {
+		if job.DocumentID != documentID {
+			continue
+		}
+		if job.Status == domain.ProcessingStatusPending || job.Status == domain.ProcessingStatusProcessing {
+			return job, true
+		}
+	}
+	return domain.Job{}, false
+}
+
+func (q *Queue) Cancel(id string) error {
+	q.mu.Lock()
+	job, ok := q.jobs[id]
+	if !ok {
+		q.mu.Unlock()
+		return fmt.Errorf("job %s not found", id)
+	}
+	if cancel, running := q.running[id]; running {
+		q.mu.Unlock()
+		cancel()
+		return nil
+	}
+	q.mu.Unlock()
+
+	if job.Status == domain.ProcessingStatusPending {
+		q.finish(id, ErrCancelled)
+	}
+	return nil
+}
+
+func (q *Queue) Wait(ctx context.Context, id string) error {
+	q.mu.RLock()
+	done, running := q.done[id]
+	q.mu.RUnlock()
+	if !running {
+		return nil
+	}
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (q *Queue) InterruptProcessing() []domain.Job {
+	interrupted := make([]domain.Job, 0)
+	for _, job := range q.List() {
+		if job.Status != domain.ProcessingStatusProcessing {
+			continue
+		}
+		q.mu.RLock()
+		_, running := q.running[job.ID]
+		q.mu.RUnlock()
+		if running {
+			continue
+		}
+		q.finish(job.ID, ErrInterrupted)
+		interrupted = append(interrupted, job)
+	}
+	return interrupted
+}
+
+func (q *Queue) Prune(olderThan time.Duration) int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	cutoff := time.Now().Add(-olderThan).Unix()
+	pruned := 0
+	for id, job := range q.jobs {
+		if job.Status != domain.ProcessingStatusCompleted && job.Status != domain.ProcessingStatusFailed {
+			continue
+		}
+		if job.UpdatedAt >= cutoff {
+			continue
+		}
+		if err := os.Remove(filepath.Join(q.dir, id+".json")); err != nil && !errors.Is(err, os.ErrNotExist) {
+			log.Printf("failed to prune job %s: %v", id, err)
+			continue
+		}
+		delete(q.jobs, id)
+		pruned++
+	}
+	return pruned
+}
+
+func (q *Queue) pruneLoop(ctx context.Context) {
+	defer q.wg.Done()
+
+	ticker := time.NewTicker(min(q.retention, pruneInterval))
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if pruned := q.Prune(q.retention); pruned > 0 {
+				log.Printf("pruned %d finished jobs", pruned)
+			}
+		}
+	}
+}
+
+func (q *Queue) work(ctx context.Context) {
+	defer q.wg.Done()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case id := <-q.pending:
+			q.run(ctx, id)
+		}
+	}
+}
+
+func (q *Queue) run(ctx context.Context, id string) {
+	q.mu.Lock()
+	job, ok := q.jobs[id]
+	if !ok || job.Status != domain.ProcessingStatusPending {
+		q.mu.Unlock()
+		return
+	}
+	handler := q.handlers[job.Type]
+	job.Status = domain.ProcessingStatusProcessing
+	job.UpdatedAt = time.Now().Unix()
+	if err := q.saveLocked(job); err != nil {
+		log.Printf("failed to persist job %s state: %v", job.ID, err)
+	}
+	q.jobs[id] = job
+	jobCtx, cancel := context.WithCancelCause(ctx)
+	q.running[id] = func() { cancel(ErrCancelled) }
+	done := make(chan struct{})
+	q.done[id] = done
+	q.mu.Unlock()
+
+	var err error
+	if handler == nil {
+		err = fmt.Errorf("no handler registered for job type %s", job.Type)
+	} else {
+		err = runHandler(jobCtx, handler, job)
+	}
+	if cause := context.Cause(jobCtx); errors.Is(cause, ErrCancelled) {
+		err = cause
+	} else if ctx.Err() != nil {
+		err = ErrInterrupted
+	}
+	cancel(nil)
+
+	q.mu.Lock()
+	delete(q.running, id)
+	q.mu.Unlock()
+
+	if err != nil {
+		log.Printf("job %s (%s) failed: %v", job.ID, job.Type, err)
+	}
+	q.finish(id, err)
+
+	q.mu.Lock()
+	delete(q.done, id)
+	q.mu.Unlock()
+	close(done)
+}
+
+func runHandler(ctx context.Context, handler Handler, job domain.Job) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job panicked: %v", r)
+		}
+	}()
+	return handler(ctx, job)
+}
+
+func (q *Queue) finish(id string, err error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	job, ok := q.jobs[id]
+	if !ok {
+		return
+	}
+
+	job.Status = domain.ProcessingStatusCompleted
+	job.Error = ""
+	if err != nil {
+		job.Status = domain.ProcessingStatusFailed
+		job.Error = err.Error()
+	}
+	job.UpdatedAt = time.Now().Unix()
+	q.jobs[id] = job
+
+	if saveErr := q.saveLocked(job); saveErr != nil {
+		log.Printf("failed to persist job %s state: %v", job.ID, saveErr)
+	}
+}
+
+func (q *Queue) load() error {
+	entries, err := os.ReadDir(q.dir)
+	if err != nil {
+		return fmt.Errorf("read jobs directory: %w", err)
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(q.dir, entry.Name()))
+		if err != nil {
+			return fmt.Errorf("read job file: %w", err)
+		}
+
+		var job domain.Job
+		if err := json.Unmarshal(data, &job); err != nil {
+			log.Printf("skipping unreadable job file %s: %v", entry.Name(), err)
+			continue
+		}
+		if job.ID == "" {
+			continue
+		}
+		q.jobs[job.ID] = job
+	}
+
+	return nil
+}
+
+func (q *Queue) saveLocked(job domain.Job) error {
+	tmp, err := os.CreateTemp(q.dir, "job-*.tmp")
+	if err != nil {
+		return fmt.Errorf("create temp job: %w", err)
+	}
+
+	encoder := json.NewEncoder(tmp)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(job); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("encode job: %w", err)
+	}
+
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("close temp job: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), filepath.Join(q.dir, job.ID+".json")); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("replace job file: %w", err)
+	}
+
+	return nil
+}
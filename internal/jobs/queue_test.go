@@ -0,0 +1,266 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"myProfessor/internal/domain"
+)
+
+func waitForStatus(t *testing.T, q *Queue, id, status string) domain.Job {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		job, err := q.Get(id)
+		if err != nil {
+			t.Fatalf("get job: %v", err)
+		}
+		if job.Status == status {
+			return job
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	job, _ := q.Get(id)
+	t.Fatalf("job %s stayed %q, expected %q", id, job.Status, status)
+	return domain.Job{}
+}
+
+func TestQueuePersistsAndReloadsJobs(t *testing.T) {
+	dir := t.TempDir()
+	q, err := NewQueue(dir, 1, 0)
+	if err != nil {
+		t.Fatalf("new queue: %v", err)
+	}
+	q.Register("noop", func(ctx context.Context, job domain.Job) error { return nil })
+	q.Register("fail", func(ctx context.Context, job domain.Job) error { return errors.New("boom") })
+
+	done, err := q.Enqueue("noop", "doc-1")
+	if err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+	failed, err := q.Enqueue("fail", "doc-2")
+	if err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+	if _, err := q.Enqueue("unknown", "doc-3"); err == nil {
+		t.Fatal("expected an error for an unregistered job type")
+	}
+
+	q.Start()
+	waitForStatus(t, q, done.ID, domain.ProcessingStatusCompleted)
+	waitForStatus(t, q, failed.ID, domain.ProcessingStatusFailed)
+	q.Stop()
+
+	pending, err := q.Enqueue("noop", "doc-4")
+	if err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+
+	reloaded, err := NewQueue(dir, 1, 0)
+	if err != nil {
+		t.Fatalf("reload queue: %v", err)
+	}
+	if job, err := reloaded.Get(failed.ID); err != nil || job.Error != "boom" || job.DocumentID != "doc-2" {
+		t.Fatalf("expected the failed job to be reloaded, got %+v (%v)", job, err)
+	}
+	if len(reloaded.ListByDocument("doc-1")) != 1 {
+		t.Fatal("expected the completed job to be reloaded")
+	}
+
+	var ran sync.WaitGroup
+	ran.Add(1)
+	reloaded.Register("noop", func(ctx context.Context, job domain.Job) error {
+		ran.Done()
+		return nil
+	})
+	reloaded.Start()
+	defer reloaded.Stop()
+	waitForStatus(t, reloaded, pending.ID, domain.ProcessingStatusCompleted)
+	ran.Wait()
+}
+
+func TestQueueRunsJobsConcurrently(t *testing.T) {
+	const workers = 3
+	q, err := NewQueue(t.TempDir(), workers, 0)
+	if err != nil {
+		t.Fatalf("new queue: %v", err)
+	}
+
+	started := make(chan struct{}, workers)
+	release := make(chan struct{})
+	q.Register("block", func(ctx context.Context, job domain.Job) error {
+		started <- struct{}{}
+		<-release
+		return nil
+	})
+	q.Start()
+	defer q.Stop()
+
+	ids := make([]string, 0, workers)
+	for i := 0; i < workers; i++ {
+		job, err := q.Enqueue("block", "doc")
+		if err != nil {
+			t.Fatalf("enqueue: %v", err)
+		}
+		ids = append(ids, job.ID)
+	}
+
+	for i := 0; i < workers; i++ {
+		select {
+		case <-started:
+		case <-time.After(2 * time.Second):
+			t.Fatalf("only %d of %d jobs started in parallel", i, workers)
+		}
+	}
+	close(release)
+	for _, id := range ids {
+		waitForStatus(t, q, id, domain.ProcessingStatusCompleted)
+	}
+}
+
+func TestQueueRejectsDuplicateActiveJob(t *testing.T) {
+	q, err := NewQueue(t.TempDir(), 1, 0)
+	if err != nil {
+		t.Fatalf("new queue: %v", err)
+	}
+	release := make(chan struct{})
+	q.Register("block", func(ctx context.Context, job domain.Job) error {
+		<-release
+		return nil
+	})
+
+	var wg sync.WaitGroup
+	results := make(chan error, 10)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := q.EnqueueUnique("block", "doc-1")
+			results <- err
+		}()
+	}
+	wg.Wait()
+	close(results)
+
+	accepted := 0
+	for err := range results {
+		switch {
+		case err == nil:
+			accepted++
+		case !errors.Is(err, ErrActiveJob):
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if accepted != 1 {
+		t.Fatalf("expected exactly one job to be accepted, got %d", accepted)
+	}
+
+	if _, err := q.EnqueueUnique("block", "doc-2"); err != nil {
+		t.Fatalf("expected another document to be accepted: %v", err)
+	}
+
+	q.Start()
+	defer q.Stop()
+	close(release)
+	active, ok := q.ActiveJob("doc-1")
+	if ok {
+		waitForStatus(t, q, active.ID, domain.ProcessingStatusCompleted)
+	}
+	if _, err := q.EnqueueUnique("block", "doc-1"); err != nil {
+		t.Fatalf("expected a new job once the previous one finished: %v", err)
+	}
+}
+
+func TestQueuePrunesFinishedJobs(t *testing.T) {
+	dir := t.TempDir()
+	q, err := NewQueue(dir, 1, 0)
+	if err != nil {
+		t.Fatalf("new queue: %v", err)
+	}
+	q.Register("noop", func(ctx context.Context, job domain.Job) error { return nil })
+
+	finished, err := q.Enqueue("noop", "doc-1")
+	if err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+	q.finish(finished.ID, nil)
+	pending, err := q.Enqueue("noop", "doc-2")
+	if err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+
+	if pruned := q.Prune(time.Hour); pruned != 0 {
+		t.Fatalf("expected recent jobs to be kept, pruned %d", pruned)
+	}
+
+	q.mu.Lock()
+	old := q.jobs[finished.ID]
+	old.UpdatedAt = time.Now().Add(-2 * time.Hour).Unix()
+	q.jobs[finished.ID] = old
+	stale := q.jobs[pending.ID]
+	stale.UpdatedAt = old.UpdatedAt
+	q.jobs[pending.ID] = stale
+	q.mu.Unlock()
+
+	if pruned := q.Prune(time.Hour); pruned != 1 {
+		t.Fatalf("expected one finished job to be pruned, got %d", pruned)
+	}
+	if _, err := q.Get(finished.ID); err == nil {
+		t.Fatal("expected the finished job to be forgotten")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "jobs", finished.ID+".json")); !os.IsNotExist(err) {
+		t.Fatalf("expected the job file to be removed, got %v", err)
+	}
+	if _, err := q.Get(pending.ID); err != nil {
+		t.Fatal("pending jobs must never be pruned")
+	}
+}
+
+func TestQueueWaitReturnsAfterCancelledJobStops(t *testing.T) {
+	q, err := NewQueue(t.TempDir(), 1, 0)
+	if err != nil {
+		t.Fatalf("new queue: %v", err)
+	}
+	started := make(chan struct{})
+	var stopped atomic.Bool
+	q.Register("slow", func(ctx context.Context, job domain.Job) error {
+		close(started)
+		<-ctx.Done()
+		time.Sleep(50 * time.Millisecond)
+		stopped.Store(true)
+		return ctx.Err()
+	})
+	q.Start()
+	defer q.Stop()
+
+	job, err := q.Enqueue("slow", "doc-1")
+	if err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+	<-started
+
+	if err := q.Cancel(job.ID); err != nil {
+		t.Fatalf("cancel: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := q.Wait(ctx, job.ID); err != nil {
+		t.Fatalf("wait: %v", err)
+	}
+	if !stopped.Load() {
+		t.Fatal("expected Wait to return only once the handler has returned")
+	}
+	if got, _ := q.Get(job.ID); got.Status != domain.ProcessingStatusFailed || got.Error != ErrCancelled.Error() {
+		t.Fatalf("expected a cancelled job, got %+v", got)
+	}
+	if err := q.Wait(ctx, job.ID); err != nil {
+		t.Fatalf("wait on a finished job: %v", err)
+	}
+}
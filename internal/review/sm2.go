@@ -0,0 +1,63 @@
+package review
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"myProfessor/internal/domain"
+)
+
+const (
+	InitialEaseFactor = 2.5
+	MinEaseFactor     = 1.3
+	MinGrade          = 0
+	MaxGrade          = 5
+	passingGrade      = 3
+	day               = 24 * time.Hour
+)
+
+var ErrInvalidGrade = fmt.Errorf("grade must be between %d and %d", MinGrade, MaxGrade)
+
+func NewCard(card domain.Flashcard, now time.Time) domain.Flashcard {
+	card.EaseFactor = InitialEaseFactor
+	card.Interval = 0
+	card.Repetitions = 0
+	card.DueAt = now.Unix()
+	card.LastReviewedAt = 0
+	card.LastGrade = nil
+	return card
+}
+
+func Schedule(card domain.Flashcard, grade int, now time.Time) (domain.Flashcard, error) {
+	if grade < MinGrade || grade > MaxGrade {
+		return card, ErrInvalidGrade
+	}
+	if card.EaseFactor == 0 {
+		card.EaseFactor = InitialEaseFactor
+	}
+
+	if grade < passingGrade {
+		card.Repetitions = 0
+		card.Interval = 1
+	} else {
+		switch card.Repetitions {
+		case 0:
+			card.Interval = 1
+		case 1:
+			card.Interval = 6
+		default:
+			card.Interval = int(math.Round(float64(card.Interval) * card.EaseFactor))
+		}
+		card.Repetitions++
+	}
+
+	miss := float64(MaxGrade - grade)
+	card.EaseFactor = math.Max(MinEaseFactor, card.EaseFactor+0.1-miss*(0.08+miss*0.02))
+	card.EaseFactor = math.Round(card.EaseFactor*100) / 100
+
+	card.LastGrade = &grade
+	card.LastReviewedAt = now.Unix()
+	card.DueAt = now.Add(time.Duration(card.Interval) * day).Unix()
+	return card, nil
+}
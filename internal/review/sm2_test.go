@@ -0,0 +1,55 @@
+package review
+
+import (
+	"testing"
+	"time"
+
+	"myProfessor/internal/domain"
+)
+
+func TestScheduleFollowsSM2(t *testing.T) {
+	now := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	card := NewCard(domain.Flashcard{Front: "Q", Back: "R"}, now)
+	if card.DueAt != now.Unix() || card.EaseFactor != InitialEaseFactor {
+		t.Fatalf("new card should be due immediately with default ease, got %+v", card)
+	}
+
+	steps := []struct {
+		grade    int
+		interval int
+		ease     float64
+	}{
+		{grade: 5, interval: 1, ease: 2.6},
+		{grade: 4, interval: 6, ease: 2.6},
+		{grade: 3, interval: 16, ease: 2.46},
+		{grade: 1, interval: 1, ease: 1.92},
+		{grade: 4, interval: 1, ease: 1.92},
+	}
+	for i, step := range steps {
+		var err error
+		card, err = Schedule(card, step.grade, now)
+		if err != nil {
+			t.Fatalf("step %d: %v", i, err)
+		}
+		if card.Interval != step.interval || card.EaseFactor != step.ease {
+			t.Fatalf("step %d: expected interval %d and ease %.2f, got %d and %.2f", i, step.interval, step.ease, card.Interval, card.EaseFactor)
+		}
+		if want := now.AddDate(0, 0, step.interval).Unix(); card.DueAt != want {
+			t.Fatalf("step %d: expected due %d, got %d", i, want, card.DueAt)
+		}
+	}
+	if card.Repetitions != 1 {
+		t.Fatalf("expected repetitions reset after a lapse, got %d", card.Repetitions)
+	}
+
+	for i := 0; i < 10; i++ {
+		card, _ = Schedule(card, 0, now)
+	}
+	if card.EaseFactor != MinEaseFactor {
+		t.Fatalf("expected ease factor floor %.1f, got %.2f", MinEaseFactor, card.EaseFactor)
+	}
+
+	if _, err := Schedule(card, 6, now); err == nil {
+		t.Fatal("expected an error for an out of range grade")
+	}
+}
@@ -0,0 +1,232 @@
+package search
+
+import (
+	"math"
+	"sort"
+	"sync"
+
+	"myProfessor/internal/domain"
+)
+
+const (
+	FieldTitle         = "title"
+	FieldSummary       = "summary"
+	FieldCourse        = "course"
+	FieldTranscription = "transcription"
+
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+type field struct {
+	name   string
+	weight float64
+	text   func(domain.Document) string
+}
+
+var fields = []field{
+	{name: FieldTitle, weight: 3, text: func(doc domain.Document) string { return doc.Title }},
+	{name: FieldSummary, weight: 1.5, text: func(doc domain.Document) string { return doc.Summary }},
+	{name: FieldCourse, weight: 1.5, text: func(doc domain.Document) string { return doc.Course }},
+	{name: FieldTranscription, weight: 1, text: func(doc domain.Document) string { return doc.Transcription }},
+}
+
+type Snippet struct {
+	Field string `json:"field"`
+	Text  string `json:"text"`
+}
+
+type Result struct {
+	DocumentID string    `json:"documentId"`
+	FolderID   string    `json:"folderId"`
+	Title      string    `json:"title"`
+	Score      float64   `json:"score"`
+	Snippets   []Snippet `json:"snippets"`
+}
+
+type indexedDocument struct {
+	folderID string
+	title    string
+	texts    []string
+	lengths  []int
+	terms    []string
+}
+
+type Index struct {
+	mu       sync.RWMutex
+	docs     map[string]*indexedDocument
+	postings map[string]map[string][]int
+	totals   []int
+}
+
+func NewIndex() *Index {
+	return &Index{
+		docs:     map[string]*indexedDocument{},
+		postings: map[string]map[string][]int{},
+		totals:   make([]int, len(fields)),
+	}
+}
+
+func (idx *Index) Rebuild(docs []domain.Document) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	idx.docs = map[string]*indexedDocument{}
+	idx.postings = map[string]map[string][]int{}
+	idx.totals = make([]int, len(fields))
+	for _, doc := range docs {
+		idx.addLocked(doc)
+	}
+}
+
+func (idx *Index) Apply(doc domain.Document, deleted bool) {
+	if deleted {
+		idx.Remove(doc.ID)
+		return
+	}
+	idx.Upsert(doc)
+}
+
+func (idx *Index) Upsert(doc domain.Document) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	idx.removeLocked(doc.ID)
+	idx.addLocked(doc)
+}
+
+func (idx *Index) Remove(id string) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	idx.removeLocked(id)
+}
+
+func (idx *Index) Len() int {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	return len(idx.docs)
+}
+
+func (idx *Index) Search(query, folderID string, limit int) []Result {
+	terms := queryTerms(query)
+	if len(terms) == 0 {
+		return []Result{}
+	}
+
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	total := float64(len(idx.docs))
+	averages := make([]float64, len(fields))
+	for i, sum := range idx.totals {
+		if len(idx.docs) > 0 {
+			averages[i] = float64(sum) / total
+		}
+	}
+
+	scores := map[string]float64{}
+	for _, term := range terms {
+		postings := idx.postings[term]
+		if len(postings) == 0 {
+			continue
+		}
+
+		df := float64(len(postings))
+		idf := math.Log(1 + (total-df+0.5)/(df+0.5))
+		for docID, counts := range postings {
+			doc := idx.docs[docID]
+			if folderID != "" && doc.folderID != folderID {
+				continue
+			}
+
+			weighted := 0.0
+			for i, count := range counts {
+				if count == 0 || averages[i] == 0 {
+					continue
+				}
+				norm := 1 - bm25B + bm25B*float64(doc.lengths[i])/averages[i]
+				weighted += fields[i].weight * float64(count) / norm
+			}
+			scores[docID] += idf * weighted * (bm25K1 + 1) / (weighted + bm25K1)
+		}
+	}
+
+	results := make([]Result, 0, len(scores))
+	for docID, score := range scores {
+		doc := idx.docs[docID]
+		results = append(results, Result{
+			DocumentID: docID,
+			FolderID:   doc.folderID,
+			Title:      doc.title,
+			Score:      math.Round(score*1000) / 1000,
+			Snippets:   doc.snippets(terms),
+		})
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].DocumentID < results[j].DocumentID
+	})
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results
+}
+
+func (idx *Index) addLocked(doc domain.Document) {
+	indexed := &indexedDocument{
+		folderID: doc.FolderID,
+		title:    doc.Title,
+		texts:    make([]string, len(fields)),
+		lengths:  make([]int, len(fields)),
+	}
+
+	for i, f := range fields {
+		text := f.text(doc)
+		indexed.texts[i] = text
+
+		tokens := tokenize(text)
+		indexed.lengths[i] = len(tokens)
+		idx.totals[i] += len(tokens)
+
+		for _, tok := range tokens {
+			postings, ok := idx.postings[tok.term]
+			if !ok {
+				postings = map[string][]int{}
+				idx.postings[tok.term] = postings
+			}
+			counts, ok := postings[doc.ID]
+			if !ok {
+				counts = make([]int, len(fields))
+				postings[doc.ID] = counts
+				indexed.terms = append(indexed.terms, tok.term)
+			}
+			counts[i]++
+		}
+	}
+
+	idx.docs[doc.ID] = indexed
+}
+
+func (idx *Index) removeLocked(id string) {
+	doc, ok := idx.docs[id]
+	if !ok {
+		return
+	}
+
+	for _, term := range doc.terms {
+		postings := idx.postings[term]
+		delete(postings, id)
+		if len(postings) == 0 {
+			delete(idx.postings, term)
+		}
+	}
+	for i, length := range doc.lengths {
+		idx.totals[i] -= length
+	}
+	delete(idx.docs, id)
+}
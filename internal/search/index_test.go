@@ -0,0 +1,82 @@
+package search
+
+import (
+	"strings"
+	"testing"
+
+	"myProfessor/internal/domain"
+)
+
+func TestSearchIsAccentInsensitiveAndRanked(t *testing.T) {
+	idx := NewIndex()
+	idx.Rebuild([]domain.Document{
+		{ID: "d1", FolderID: "algebre", Title: "Valeurs propres", Transcription: "Aujourd'hui on étudie les valeurs propres d'une matrice et leurs vecteurs propres."},
+		{ID: "d2", FolderID: "algebre", Title: "Déterminants", Transcription: "Le déterminant permet de trouver une valeur propre."},
+		{ID: "d3", FolderID: "analyse", Title: "Intégrales", Course: "# Intégrales\n\nUne intégrale généralise la somme."},
+	})
+
+	results := idx.Search("valeur propre", "", 10)
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results, got %+v", results)
+	}
+	if results[0].DocumentID != "d1" {
+		t.Fatalf("expected the lecture titled on the topic first, got %s", results[0].DocumentID)
+	}
+
+	results = idx.Search("INTEGRALE", "", 10)
+	if len(results) != 1 || results[0].DocumentID != "d3" {
+		t.Fatalf("expected accent-insensitive match on d3, got %+v", results)
+	}
+	if len(results[0].Snippets) != 2 || results[0].Snippets[1].Field != FieldCourse {
+		t.Fatalf("expected title and course snippets, got %+v", results[0].Snippets)
+	}
+	if !strings.Contains(results[0].Snippets[1].Text, "<mark>intégrale</mark>") {
+		t.Fatalf("expected highlighted snippet, got %q", results[0].Snippets[1].Text)
+	}
+
+	if results := idx.Search("valeur propre", "analyse", 10); len(results) != 0 {
+		t.Fatalf("expected folder filter to exclude other folders, got %+v", results)
+	}
+}
+
+func TestIndexTracksUpdatesAndDeletes(t *testing.T) {
+	idx := NewIndex()
+	doc := domain.Document{ID: "d1", Title: "Cours", Summary: "Les vecteurs propres"}
+	idx.Apply(doc, false)
+
+	doc.Summary = "Les espaces vectoriels"
+	idx.Apply(doc, false)
+	if results := idx.Search("propres", "", 10); len(results) != 0 {
+		t.Fatalf("expected stale terms to be removed, got %+v", results)
+	}
+	if results := idx.Search("espace", "", 10); len(results) != 1 || results[0].Snippets[0].Field != FieldSummary {
+		t.Fatalf("expected updated summary to be indexed, got %+v", results)
+	}
+
+	idx.Apply(doc, true)
+	if idx.Len() != 0 || len(idx.postings) != 0 {
+		t.Fatalf("expected empty index after delete")
+	}
+}
+
+func TestBuildSnippetTrimsLongText(t *testing.T) {
+	text := strings.Repeat("introduction générale du chapitre ", 10) + "ici le théorème spectral " + strings.Repeat("suite du cours ", 20)
+	snippet := buildSnippet(text, matchesFor(text, "theoreme"))
+
+	if !strings.HasPrefix(snippet, "…") || !strings.HasSuffix(snippet, "…") {
+		t.Fatalf("expected ellipsis on both sides, got %q", snippet)
+	}
+	if !strings.Contains(snippet, "<mark>théorème</mark>") {
+		t.Fatalf("expected highlight, got %q", snippet)
+	}
+}
+
+func matchesFor(text, term string) []token {
+	matches := make([]token, 0)
+	for _, tok := range tokenize(text) {
+		if tok.term == term {
+			matches = append(matches, tok)
+		}
+	}
+	return matches
+}
@@ -0,0 +1,108 @@
+package search
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	snippetContext = 60
+	snippetWidth   = 160
+	highlightOpen  = "<mark>"
+	highlightClose = "</mark>"
+)
+
+func (d *indexedDocument) snippets(terms []string) []Snippet {
+	wanted := make(map[string]bool, len(terms))
+	for _, term := range terms {
+		wanted[term] = true
+	}
+
+	snippets := make([]Snippet, 0)
+	for i, f := range fields {
+		text := d.texts[i]
+
+		matches := make([]token, 0)
+		for _, tok := range tokenize(text) {
+			if wanted[tok.term] {
+				matches = append(matches, tok)
+			}
+		}
+		if len(matches) == 0 {
+			continue
+		}
+
+		snippets = append(snippets, Snippet{Field: f.name, Text: buildSnippet(text, matches)})
+	}
+	return snippets
+}
+
+func buildSnippet(text string, matches []token) string {
+	best, bestDistinct := 0, 0
+	for i, m := range matches {
+		distinct := map[string]bool{}
+		for _, other := range matches[i:] {
+			if other.start-m.start > snippetWidth-snippetContext {
+				break
+			}
+			distinct[other.term] = true
+		}
+		if len(distinct) > bestDistinct {
+			best, bestDistinct = i, len(distinct)
+		}
+	}
+
+	from := alignStart(text, matches[best].start-snippetContext)
+	to := alignEnd(text, from+snippetWidth)
+	if to < matches[best].end {
+		to = matches[best].end
+	}
+
+	var b strings.Builder
+	if from > 0 {
+		b.WriteString("…")
+	}
+	pos := from
+	for _, m := range matches {
+		if m.start < from || m.end > to {
+			continue
+		}
+		b.WriteString(text[pos:m.start])
+		b.WriteString(highlightOpen)
+		b.WriteString(text[m.start:m.end])
+		b.WriteString(highlightClose)
+		pos = m.end
+	}
+	b.WriteString(text[pos:to])
+	if to < len(text) {
+		b.WriteString("…")
+	}
+
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+func alignStart(text string, pos int) int {
+	if pos <= 0 {
+		return 0
+	}
+	if space := strings.IndexAny(text[pos:], " \n\t"); space >= 0 && space < snippetContext {
+		return pos + space + 1
+	}
+	for pos < len(text) && !utf8.RuneStart(text[pos]) {
+		pos++
+	}
+	return pos
+}
+
+func alignEnd(text string, pos int) int {
+	if pos >= len(text) {
+		return len(text)
+	}
+	if space := strings.LastIndexAny(text[:pos], " \n\t"); space > 0 && pos-space < snippetContext {
+		return space
+	}
+	for pos > 0 && !utf8.RuneStart(text[pos]) {
+		pos--
+	}
+	return pos
+}
@@ -0,0 +1,105 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+var frenchStopwords = map[string]struct{}{
+	"a": {}, "au": {}, "aux": {}, "avec": {}, "c": {}, "ce": {}, "ces": {}, "cet": {}, "cette": {},
+	"d": {}, "dans": {}, "de": {}, "des": {}, "du": {}, "elle": {}, "en": {}, "est": {}, "et": {},
+	"il": {}, "ils": {}, "j": {}, "je": {}, "l": {}, "la": {}, "le": {}, "les": {}, "leur": {},
+	"lui": {}, "m": {}, "mais": {}, "me": {}, "n": {}, "ne": {}, "nous": {}, "on": {}, "ou": {},
+	"par": {}, "pas": {}, "pour": {}, "qu": {}, "que": {}, "qui": {}, "s": {}, "sa": {}, "se": {},
+	"ses": {}, "son": {}, "sur": {}, "t": {}, "te": {}, "un": {}, "une": {}, "vous": {}, "y": {},
+}
+
+type token struct {
+	term  string
+	start int
+	end   int
+}
+
+func tokenize(text string) []token {
+	tokens := make([]token, 0)
+
+	start := -1
+	for i, r := range text {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start >= 0 {
+			if term := normalizeTerm(text[start:i]); term != "" {
+				tokens = append(tokens, token{term: term, start: start, end: i})
+			}
+			start = -1
+		}
+	}
+	if start >= 0 {
+		if term := normalizeTerm(text[start:]); term != "" {
+			tokens = append(tokens, token{term: term, start: start, end: len(text)})
+		}
+	}
+
+	return tokens
+}
+
+func queryTerms(query string) []string {
+	seen := map[string]bool{}
+	terms := make([]string, 0)
+	for _, tok := range tokenize(query) {
+		if !seen[tok.term] {
+			seen[tok.term] = true
+			terms = append(terms, tok.term)
+		}
+	}
+	return terms
+}
+
+func normalizeTerm(word string) string {
+	folded := foldAccents(strings.ToLower(word))
+	if _, stop := frenchStopwords[folded]; stop {
+		return ""
+	}
+	return stem(folded)
+}
+
+func foldAccents(word string) string {
+	var b strings.Builder
+	for _, r := range norm.NFD.String(word) {
+		switch {
+		case unicode.Is(unicode.Mn, r):
+		case r == 'œ':
+			b.WriteString("oe")
+		case r == 'æ':
+			b.WriteString("ae")
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+func stem(word string) string {
+	if len(word) <= 3 {
+		return word
+	}
+	switch {
+	case strings.HasSuffix(word, "eaux"):
+		return strings.TrimSuffix(word, "x")
+	case strings.HasSuffix(word, "aux"):
+		return strings.TrimSuffix(word, "ux") + "l"
+	case strings.HasSuffix(word, "s"), strings.HasSuffix(word, "x"):
+		return word[:len(word)-1]
+	}
+	return word
+}
+
+func Terms(text string) []string {
+	return queryTerms(text)
+}
@@ -0,0 +1,86 @@
+package semantic
+
+import (
+	"strings"
+
+	"myProfessor/internal/domain"
+)
+
+const (
+	chunkWords        = 180
+	chunkOverlapWords = 30
+)
+
+type Chunk struct {
+	Index int     `json:"index"`
+	Text  string  `json:"text"`
+	Start float64 `json:"start"`
+	End   float64 `json:"end"`
+}
+
+func ChunkDocument(doc domain.Document) []Chunk {
+	if len(doc.Segments) > 0 {
+		return chunkSegments(doc.Segments)
+	}
+	return chunkText(doc.Transcription)
+}
+
+func chunkSegments(segments []domain.Segment) []Chunk {
+	chunks := make([]Chunk, 0)
+
+	first := 0
+	for first < len(segments) {
+		words := 0
+		last := first
+		for last < len(segments) {
+			words += len(strings.Fields(segments[last].Text))
+			last++
+			if words >= chunkWords {
+				break
+			}
+		}
+
+		texts := make([]string, 0, last-first)
+		for _, segment := range segments[first:last] {
+			if text := strings.TrimSpace(segment.Text); text != "" {
+				texts = append(texts, text)
+			}
+		}
+		if len(texts) > 0 {
+			chunks = append(chunks, Chunk{
+				Index: len(chunks),
+				Text:  strings.Join(texts, " "),
+				Start: segments[first].Start,
+				End:   segments[last-1].End,
+			})
+		}
+		if last >= len(segments) {
+			break
+		}
+
+		next := last
+		overlap := 0
+		for next-1 > first && overlap < chunkOverlapWords {
+			next--
+			overlap += len(strings.Fields(segments[next].Text))
+		}
+		first = next
+	}
+
+	return chunks
+}
+
+func chunkText(text string) []Chunk {
+	words := strings.Fields(text)
+	chunks := make([]Chunk, 0)
+
+	for start := 0; start < len(words); start += chunkWords - chunkOverlapWords {
+		end := min(start+chunkWords, len(words))
+		chunks = append(chunks, Chunk{Index: len(chunks), Text: strings.Join(words[start:end], " ")})
+		if end == len(words) {
+			break
+		}
+	}
+
+	return chunks
+}
@@ -0,0 +1,550 @@
+package semantic
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"myProfessor/internal/domain"
+	"myProfessor/internal/services"
+)
+
+const (
+	embedBatchSize = 64
+	retryBaseDelay = 30 * time.Second
+	retryMaxDelay  = 30 * time.Minute
+)
+
+var ErrNotIndexed = errors.New("document is not indexed yet")
+
+type Match struct {
+	DocumentID string  `json:"documentId"`
+	Score      float64 `json:"score"`
+	Chunk      Chunk   `json:"chunk"`
+}
+
+type entry struct {
+	DocumentID  string      `json:"documentId"`
+	Model       string      `json:"model"`
+	ContentHash string      `json:"contentHash"`
+	Chunks      []Chunk     `json:"chunks"`
+	Vectors     [][]float32 `json:"vectors"`
+
+	centroid []float32
+}
+
+type retry struct {
+	doc domain.Document
+	at  time.Time
+}
+
+type Index struct {
+	mu         sync.RWMutex
+	dir        string
+	embedder   services.Embedder
+	entries    map[string]*entry
+	pending    map[string]domain.Document
+	retries    map[string]retry
+	attempts   map[string]int
+	generation map[string]int
+	retryBase  time.Duration
+	retryMax   time.Duration
+	wake       chan struct{}
+	cancel     context.CancelFunc
+	wg         sync.WaitGroup
+}
+
+func NewIndex(baseDir string, embedder services.Embedder) (*Index, error) {
+	dir := filepath.Join(baseDir, "embeddings")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create embeddings directory: %w", err)
+	}
+
+	idx := &Index{
+		dir:        dir,
+		embedder:   embedder,
+		entries:    map[string]*entry{},
+		pending:    map[string]domain.Document{},
+		retries:    map[string]retry{},
+		attempts:   map[string]int{},
+		generation: map[string]int{},
+		retryBase:  retryBaseDelay,
+		retryMax:   retryMaxDelay,
+		wake:       make(chan struct{}, 1),
+	}
+	if err := idx.load(); err != nil {
+		return nil, err
+	}
+	return idx, nil
+}
+
+func (idx *Index) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	idx.cancel = cancel
+
+	idx.wg.Add(1)
+	go idx.run(ctx)
+}
+
+func (idx *Index) Stop() {
+	if idx.cancel != nil {
+		idx.cancel()
+	}
+	idx.wg.Wait()
+}
+
+func (idx *Index) Sync(docs []domain.Document) {
+	known := make(map[string]bool, len(docs))
+	for _, doc := range docs {
+		known[doc.ID] = true
+		idx.Apply(doc, false)
+	}
+
+	idx.mu.RLock()
+	orphans := make([]string, 0)
+	for id := range idx.entries {
+		if !known[id] {
+			orphans = append(orphans, id)
+		}
+	}
+	idx.mu.RUnlock()
+
+	for _, id := range orphans {
+		idx.Invalidate(id)
+	}
+}
+
+func (idx *Index) Apply(doc domain.Document, deleted bool) {
+	if deleted || strings.TrimSpace(doc.Transcription) == "" {
+		idx.Invalidate(doc.ID)
+		return
+	}
+
+	hash := contentHash(doc)
+
+	idx.mu.Lock()
+	current, indexed := idx.entries[doc.ID]
+	if indexed && current.ContentHash == hash && current.Model == idx.embedder.EmbeddingModel() {
+		idx.mu.Unlock()
+		return
+	}
+	if queued, ok := idx.pending[doc.ID]; ok && contentHash(queued) == hash {
+		idx.mu.Unlock()
+		return
+	}
+	if failed, ok := idx.retries[doc.ID]; ok && contentHash(failed.doc) == hash {
+		idx.mu.Unlock()
+		return
+	}
+	delete(idx.retries, doc.ID)
+	delete(idx.attempts, doc.ID)
+	idx.pending[doc.ID] = doc
+	idx.mu.Unlock()
+
+	select {
+	case idx.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (idx *Index) Invalidate(documentID string) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	idx.generation[documentID]++
+	delete(idx.pending, documentID)
+	delete(idx.retries, documentID)
+	delete(idx.attempts, documentID)
+	if _, ok := idx.entries[documentID]; !ok {
+		return
+	}
+	delete(idx.entries, documentID)
+	if err := os.Remove(idx.entryPath(documentID)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		log.Printf("remove embeddings of document %s: %v", documentID, err)
+	}
+}
+
+func (idx *Index) Indexed(documentID string) bool {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	_, ok := idx.entries[documentID]
+	return ok
+}
+
+func (idx *Index) Search(ctx context.Context, query string, allow func(documentID string) bool, limit int) ([]Match, error) {
+	vector, err := idx.embedQuery(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	matches := make([]Match, 0)
+	for id, e := range idx.entries {
+		if allow != nil && !allow(id) {
+			continue
+		}
+		for i, chunkVector := range e.Vectors {
+			if len(chunkVector) != len(vector) {
+				continue
+			}
+			matches = append(matches, Match{DocumentID: id, Score: dot(vector, chunkVector), Chunk: e.Chunks[i]})
+		}
+	}
+	return topMatches(matches, limit), nil
+}
+
+func (idx *Index) SearchDocuments(ctx context.Context, query string, allow func(documentID string) bool, limit int) ([]Match, error) {
+	vector, err := idx.embedQuery(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	matches := make([]Match, 0, len(idx.entries))
+	for id, e := range idx.entries {
+		if allow != nil && !allow(id) {
+			continue
+		}
+		if best, ok := e.bestChunk(vector); ok {
+			matches = append(matches, best)
+		}
+	}
+	return topMatches(matches, limit), nil
+}
+
+func (idx *Index) Related(documentID string, limit int) ([]Match, error) {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	source, ok := idx.entries[documentID]
+	if !ok {
+		return nil, ErrNotIndexed
+	}
+
+	matches := make([]Match, 0, len(idx.entries))
+	for id, e := range idx.entries {
+		if id == documentID || len(e.centroid) != len(source.centroid) {
+			continue
+		}
+		best, ok := e.bestChunk(source.centroid)
+		if !ok {
+			continue
+		}
+		best.Score = dot(source.centroid, e.centroid)
+		matches = append(matches, best)
+	}
+	return topMatches(matches, limit), nil
+}
+
+func (idx *Index) run(ctx context.Context) {
+	defer idx.wg.Done()
+
+	for {
+		var timer *time.Timer
+		var due <-chan time.Time
+		if delay, ok := idx.nextRetry(); ok {
+			timer = time.NewTimer(delay)
+			due = timer.C
+		}
+
+		select {
+		case <-ctx.Done():
+			if timer != nil {
+				timer.Stop()
+			}
+			return
+		case <-idx.wake:
+		case <-due:
+			idx.requeueDue()
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+
+		for {
+			doc, generation, ok := idx.next()
+			if !ok {
+				break
+			}
+			if err := idx.embedDocument(ctx, doc, generation); err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				if delay, ok := idx.scheduleRetry(doc, generation); ok {
+					log.Printf("embedding of document %s failed, retrying in %s: %v", doc.ID, delay, err)
+				} else {
+					log.Printf("embedding of document %s failed: %v", doc.ID, err)
+				}
+			}
+		}
+	}
+}
+
+func (idx *Index) scheduleRetry(doc domain.Document, generation int) (time.Duration, bool) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	if idx.generation[doc.ID] != generation {
+		return 0, false
+	}
+	if _, queued := idx.pending[doc.ID]; queued {
+		return 0, false
+	}
+
+	delay := idx.retryBase << min(idx.attempts[doc.ID], 16)
+	if delay <= 0 || delay > idx.retryMax {
+		delay = idx.retryMax
+	}
+	idx.attempts[doc.ID]++
+	idx.retries[doc.ID] = retry{doc: doc, at: time.Now().Add(delay)}
+	return delay, true
+}
+
+func (idx *Index) nextRetry() (time.Duration, bool) {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	var earliest time.Time
+	for _, r := range idx.retries {
+		if earliest.IsZero() || r.at.Before(earliest) {
+			earliest = r.at
+		}
+	}
+	if earliest.IsZero() {
+		return 0, false
+	}
+	return max(time.Until(earliest), 0), true
+}
+
+func (idx *Index) requeueDue() {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	now := time.Now()
+	for id, r := range idx.retries {
+		if r.at.After(now) {
+			continue
+		}
+		delete(idx.retries, id)
+		if _, queued := idx.pending[id]; !queued {
+			idx.pending[id] = r.doc
+		}
+	}
+}
+
+func (idx *Index) next() (domain.Document, int, bool) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	for id, doc := range idx.pending {
+		delete(idx.pending, id)
+		return doc, idx.generation[id], true
+	}
+	return domain.Document{}, 0, false
+}
+
+func (idx *Index) embedDocument(ctx context.Context, doc domain.Document, generation int) error {
+	chunks := ChunkDocument(doc)
+	if len(chunks) == 0 {
+		return nil
+	}
+
+	vectors := make([][]float32, 0, len(chunks))
+	for start := 0; start < len(chunks); start += embedBatchSize {
+		batch := chunks[start:min(start+embedBatchSize, len(chunks))]
+		inputs := make([]string, len(batch))
+		for i, chunk := range batch {
+			inputs[i] = chunk.Text
+		}
+
+		embedded, err := idx.embedder.Embed(ctx, inputs)
+		if err != nil {
+			return err
+		}
+		for _, vector := range embedded {
+			vectors = append(vectors, normalize(vector))
+		}
+	}
+
+	e := &entry{
+		DocumentID:  doc.ID,
+		Model:       idx.embedder.EmbeddingModel(),
+		ContentHash: contentHash(doc),
+		Chunks:      chunks,
+		Vectors:     vectors,
+		centroid:    centroid(vectors),
+	}
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	if idx.generation[doc.ID] != generation {
+		return nil
+	}
+	if err := idx.saveLocked(e); err != nil {
+		return err
+	}
+	idx.entries[doc.ID] = e
+	delete(idx.attempts, doc.ID)
+	return nil
+}
+
+func (idx *Index) embedQuery(ctx context.Context, query string) ([]float32, error) {
+	vectors, err := idx.embedder.Embed(ctx, []string{query})
+	if err != nil {
+		return nil, fmt.Errorf("embed query: %w", err)
+	}
+	if len(vectors) != 1 {
+		return nil, errors.New("embed query: no vector returned")
+	}
+	return normalize(vectors[0]), nil
+}
+
+func (idx *Index) load() error {
+	paths, err := filepath.Glob(filepath.Join(idx.dir, "*.json"))
+	if err != nil {
+		return fmt.Errorf("list embeddings: %w", err)
+	}
+
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("read embeddings %s: %w", path, err)
+		}
+
+		var e entry
+		if err := json.Unmarshal(data, &e); err != nil {
+			log.Printf("ignoring unreadable embeddings file %s: %v", path, err)
+			continue
+		}
+		if e.DocumentID == "" || len(e.Chunks) != len(e.Vectors) {
+			log.Printf("ignoring inconsistent embeddings file %s", path)
+			continue
+		}
+		e.centroid = centroid(e.Vectors)
+		idx.entries[e.DocumentID] = &e
+	}
+	return nil
+}
+
+func (idx *Index) saveLocked(e *entry) error {
+	tmp, err := os.CreateTemp(idx.dir, "embeddings-*.tmp")
+	if err != nil {
+		return fmt.Errorf("create temp embeddings: %w", err)
+	}
+
+	if err := json.NewEncoder(tmp).Encode(e); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("encode embeddings: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("close temp embeddings: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), idx.entryPath(e.DocumentID)); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("replace embeddings file: %w", err)
+	}
+	return nil
+}
+
+func (idx *Index) entryPath(documentID string) string {
+	return filepath.Join(idx.dir, filepath.Base(documentID)+".json")
+}
+
+func (e *entry) bestChunk(vector []float32) (Match, bool) {
+	best := Match{Score: math.Inf(-1)}
+	for i, chunkVector := range e.Vectors {
+		if score := dot(vector, chunkVector); score > best.Score {
+			best = Match{DocumentID: e.DocumentID, Score: score, Chunk: e.Chunks[i]}
+		}
+	}
+	return best, best.DocumentID != ""
+}
+
+func contentHash(doc domain.Document) string {
+	h := sha256.New()
+	h.Write([]byte(doc.Transcription))
+	for _, segment := range doc.Segments {
+		fmt.Fprintf(h, "\x00%.3f-%.3f", segment.Start, segment.End)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func topMatches(matches []Match, limit int) []Match {
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+		return matches[i].DocumentID < matches[j].DocumentID
+	})
+	if limit > 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+	for i := range matches {
+		matches[i].Score = math.Round(matches[i].Score*1000) / 1000
+	}
+	return matches
+}
+
+func dot(a, b []float32) float64 {
+	if len(a) != len(b) {
+		return math.Inf(-1)
+	}
+	sum := 0.0
+	for i := range a {
+		sum += float64(a[i]) * float64(b[i])
+	}
+	return sum
+}
+
+func normalize(vector []float32) []float32 {
+	norm := 0.0
+	for _, v := range vector {
+		norm += float64(v) * float64(v)
+	}
+	if norm == 0 {
+		return vector
+	}
+
+	norm = math.Sqrt(norm)
+	out := make([]float32, len(vector))
+	for i, v := range vector {
+		out[i] = float32(float64(v) / norm)
+	}
+	return out
+}
+
+func centroid(vectors [][]float32) []float32 {
+	if len(vectors) == 0 {
+		return nil
+	}
+
+	sum := make([]float32, len(vectors[0]))
+	for _, vector := range vectors {
+		if len(vector) != len(sum) {
+			continue
+		}
+		for i, v := range vector {
+			sum[i] += v
+		}
+	}
+	return normalize(sum)
+}
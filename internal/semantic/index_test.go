@@ -0,0 +1,173 @@
+package semantic
+
+import (
+	"context"
+	"errors"
+	"hash/fnv"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"myProfessor/internal/domain"
+)
+
+type wordEmbedder struct{}
+
+func (wordEmbedder) EmbeddingModel() string { return "test-words" }
+
+func (wordEmbedder) Embed(ctx context.Context, inputs []string) ([][]float32, error) {
+	vectors := make([][]float32, len(inputs))
+	for i, input := range inputs {
+		vector := make([]float32, 64)
+		for _, word := range strings.Fields(strings.ToLower(input)) {
+			h := fnv.New32a()
+			h.Write([]byte(word))
+			vector[h.Sum32()%64]++
+		}
+		vectors[i] = vector
+	}
+	return vectors, nil
+}
+
+type flakyEmbedder struct {
+	wordEmbedder
+	failures atomic.Int32
+	calls    atomic.Int32
+}
+
+func (f *flakyEmbedder) Embed(ctx context.Context, inputs []string) ([][]float32, error) {
+	f.calls.Add(1)
+	if f.failures.Add(-1) >= 0 {
+		return nil, errors.New("embedding service unavailable")
+	}
+	return f.wordEmbedder.Embed(ctx, inputs)
+}
+
+func waitIndexed(t *testing.T, idx *Index, ids ...string) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		done := true
+		for _, id := range ids {
+			done = done && idx.Indexed(id)
+		}
+		if done {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("documents %v were not indexed in time", ids)
+}
+
+func TestIndexSearchRelatedAndInvalidate(t *testing.T) {
+	dir := t.TempDir()
+	idx, err := NewIndex(dir, wordEmbedder{})
+	if err != nil {
+		t.Fatalf("new index: %v", err)
+	}
+	idx.Start()
+	defer idx.Stop()
+
+	idx.Sync([]domain.Document{
+		{ID: "algebre", Transcription: "matrice valeurs propres vecteurs propres diagonalisation"},
+		{ID: "algebre2", Transcription: "diagonalisation matrice vecteurs propres"},
+		{ID: "biologie", Transcription: "photosynthese chlorophylle plantes lumiere"},
+		{ID: "vide", Transcription: "   "},
+	})
+	waitIndexed(t, idx, "algebre", "algebre2", "biologie")
+	if idx.Indexed("vide") {
+		t.Fatalf("documents without transcription must not be indexed")
+	}
+
+	matches, err := idx.SearchDocuments(context.Background(), "chlorophylle des plantes", nil, 2)
+	if err != nil {
+		t.Fatalf("search: %v", err)
+	}
+	if len(matches) != 2 || matches[0].DocumentID != "biologie" {
+		t.Fatalf("expected biology lecture first, got %+v", matches)
+	}
+
+	related, err := idx.Related("algebre", 1)
+	if err != nil {
+		t.Fatalf("related: %v", err)
+	}
+	if len(related) != 1 || related[0].DocumentID != "algebre2" {
+		t.Fatalf("expected the other algebra lecture to be related, got %+v", related)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "embeddings", "algebre.json")); err != nil {
+		t.Fatalf("expected vectors on disk: %v", err)
+	}
+	idx.Invalidate("algebre")
+	if _, err := idx.Related("algebre", 1); err != ErrNotIndexed {
+		t.Fatalf("expected ErrNotIndexed after invalidation, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "embeddings", "algebre.json")); !os.IsNotExist(err) {
+		t.Fatalf("expected vectors file to be removed, got %v", err)
+	}
+
+	reloaded, err := NewIndex(dir, wordEmbedder{})
+	if err != nil {
+		t.Fatalf("reload index: %v", err)
+	}
+	if !reloaded.Indexed("biologie") || reloaded.Indexed("algebre") {
+		t.Fatalf("expected persisted vectors to be reloaded")
+	}
+}
+
+func TestIndexRetriesFailedEmbeddings(t *testing.T) {
+	embedder := &flakyEmbedder{}
+	embedder.failures.Store(2)
+
+	idx, err := NewIndex(t.TempDir(), embedder)
+	if err != nil {
+		t.Fatalf("new index: %v", err)
+	}
+	idx.retryBase = 10 * time.Millisecond
+	idx.Start()
+	defer idx.Stop()
+
+	doc := domain.Document{ID: "algebre", Transcription: "matrice valeurs propres"}
+	idx.Apply(doc, false)
+	idx.Sync([]domain.Document{doc})
+	waitIndexed(t, idx, "algebre")
+
+	if calls := embedder.calls.Load(); calls != 3 {
+		t.Fatalf("expected two failed attempts then a success, got %d calls", calls)
+	}
+
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	if len(idx.retries) != 0 || len(idx.attempts) != 0 {
+		t.Fatalf("expected retry state to be cleared, got %v retries and %v attempts", idx.retries, idx.attempts)
+	}
+}
+
+func TestChunkSegmentsKeepsTimestamps(t *testing.T) {
+	segments := make([]domain.Segment, 0)
+	for i := 0; i < 40; i++ {
+		segments = append(segments, domain.Segment{
+			Start: float64(i * 10),
+			End:   float64(i*10 + 10),
+			Text:  strings.Repeat("mot ", 10),
+		})
+	}
+
+	chunks := ChunkDocument(domain.Document{Transcription: "ignored", Segments: segments})
+	if len(chunks) < 2 {
+		t.Fatalf("expected several chunks, got %d", len(chunks))
+	}
+	if chunks[0].Start != 0 || chunks[0].End != 180 {
+		t.Fatalf("unexpected first chunk bounds %v-%v", chunks[0].Start, chunks[0].End)
+	}
+	if chunks[1].Start >= chunks[0].End {
+		t.Fatalf("expected overlapping chunks, got %v after %v", chunks[1].Start, chunks[0].End)
+	}
+	if last := chunks[len(chunks)-1]; last.End != 400 {
+		t.Fatalf("expected last chunk to end at 400, got %v", last.End)
+	}
+}
@@ -0,0 +1,59 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"myProfessor/internal/domain"
+)
+
+const chatSystemPrompt = "Tu es un assistant pédagogique. Réponds à la question de l'étudiant uniquement à partir des extraits de cours fournis. Cite chaque information avec le numéro de l'extrait entre crochets, par exemple [2]. Si les extraits ne permettent pas de répondre, dis-le clairement."
+
+const chatHistoryMessages = 10
+
+type Passage struct {
+	DocumentID string
+	Title      string
+	Start      float64
+	End        float64
+	Timed      bool
+	Text       string
+}
+
+func (s *OpenAIService) AnswerQuestion(ctx context.Context, passages []Passage, history []domain.ChatMessage, question string) (string, error) {
+	messages := []chatMessage{{Role: "system", Content: chatSystemPrompt}}
+
+	if len(history) > chatHistoryMessages {
+		history = history[len(history)-chatHistoryMessages:]
+	}
+	for _, message := range history {
+		messages = append(messages, chatMessage{Role: message.Role, Content: message.Content})
+	}
+
+	messages = append(messages, chatMessage{Role: "user", Content: questionPrompt(passages, question)})
+	return s.complete(ctx, messages)
+}
+
+func questionPrompt(passages []Passage, question string) string {
+	var b strings.Builder
+	b.WriteString("Extraits :\n")
+	for i, passage := range passages {
+		fmt.Fprintf(&b, "[%d] %s", i+1, passage.Title)
+		if passage.Timed {
+			fmt.Fprintf(&b, " (%s – %s)", formatClock(passage.Start), formatClock(passage.End))
+		}
+		fmt.Fprintf(&b, "\n%s\n\n", passage.Text)
+	}
+	b.WriteString("Question : ")
+	b.WriteString(question)
+	return b.String()
+}
+
+func formatClock(seconds float64) string {
+	total := int(seconds)
+	if total >= 3600 {
+		return fmt.Sprintf("%d:%02d:%02d", total/3600, total/60%60, total%60)
+	}
+	return fmt.Sprintf("%02d:%02d", total/60, total%60)
+}
@@ -0,0 +1,62 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"myProfessor/internal/domain"
+)
+
+const flashcardsSystemPrompt = `Tu prépares des fiches de révision (flashcards) à partir d'un cours.
+Chaque fiche a un recto (une question ou une notion courte) et un verso (la réponse, concise et autonome).
+Évite les doublons et les fiches triviales.
+Réponds uniquement avec un objet JSON de la forme {"cards": [{"front": "...", "back": "..."}]}.`
+
+func (s *OpenAIService) GenerateFlashcards(ctx context.Context, content string, count int) (string, error) {
+	instructions := fmt.Sprintf("Nombre de fiches : %d.", count)
+	return s.completeJSON(ctx, promptMessages(flashcardsSystemPrompt, content, instructions))
+}
+
+func ParseFlashcards(raw string, count int) ([]domain.Flashcard, error) {
+	raw = strings.TrimSpace(raw)
+	raw = strings.TrimPrefix(raw, "```json")
+	raw = strings.TrimPrefix(raw, "```")
+	raw = strings.TrimSuffix(raw, "```")
+
+	var payload struct {
+		Cards []struct {
+			Front string `json:"front"`
+			Back  string `json:"back"`
+		} `json:"cards"`
+	}
+	if err := json.Unmarshal([]byte(raw), &payload); err != nil {
+		return nil, fmt.Errorf("decode flashcards: %w", err)
+	}
+
+	cards := make([]domain.Flashcard, 0, len(payload.Cards))
+	seen := map[string]bool{}
+	for i, item := range payload.Cards {
+		front := strings.TrimSpace(item.Front)
+		back := strings.TrimSpace(item.Back)
+		if front == "" || back == "" {
+			return nil, fmt.Errorf("flashcard %d must have a front and a back", i+1)
+		}
+		key := strings.ToLower(front)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		cards = append(cards, domain.Flashcard{Front: front, Back: back})
+	}
+
+	if len(cards) == 0 {
+		return nil, errors.New("no flashcards returned")
+	}
+	if count > 0 && len(cards) > count {
+		cards = cards[:count]
+	}
+	return cards, nil
+}
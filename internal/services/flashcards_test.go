@@ -0,0 +1,30 @@
+package services
+
+import "testing"
+
+func TestParseFlashcardsKeepsRequestedCount(t *testing.T) {
+	raw := "```json\n" + `{"cards":[
+		{"front":"Dérivée de x² ?","back":"2x"},
+		{"front":"dérivée de X² ?","back":"Doublon"},
+		{"front":"Primitive de 1/x ?","back":"ln|x|"},
+		{"front":"Limite de 1/x en +∞ ?","back":"0"}
+	]}` + "\n```"
+	cards, err := ParseFlashcards(raw, 2)
+	if err != nil {
+		t.Fatalf("parse flashcards: %v", err)
+	}
+	if len(cards) != 2 || cards[0].Back != "2x" || cards[1].Back != "ln|x|" {
+		t.Fatalf("expected the first two distinct cards, got %+v", cards)
+	}
+
+	invalid := map[string]string{
+		"no cards":   `{"cards":[]}`,
+		"empty back": `{"cards":[{"front":"?","back":" "}]}`,
+		"not json":   `Voici vos fiches`,
+	}
+	for name, raw := range invalid {
+		if _, err := ParseFlashcards(raw, 5); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
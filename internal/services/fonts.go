@@ -0,0 +1,204 @@
+package services
+
+import (
+	"bytes"
+	"embed"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/jung-kurt/gofpdf/v2"
+	"golang.org/x/text/unicode/norm"
+)
+
+const replacementRune = '�'
+
+//go:embed fonts/*.ttf
+var fontFiles embed.FS
+
+type fontFace struct {
+	family string
+	style  string
+	file   string
+}
+
+var fontFaces = []fontFace{
+	{family: bodyFont, style: "", file: "fonts/DejaVuSansCondensed.ttf"},
+	{family: bodyFont, style: "B", file: "fonts/DejaVuSansCondensed-Bold.ttf"},
+	{family: bodyFont, style: "I", file: "fonts/DejaVuSansCondensed-Oblique.ttf"},
+	{family: bodyFont, style: "BI", file: "fonts/DejaVuSansCondensed-BoldOblique.ttf"},
+	{family: monoFont, style: "", file: "fonts/DejaVuSansMono.ttf"},
+}
+
+type glyphSet map[rune]bool
+
+var (
+	fontsOnce sync.Once
+	fontData  map[string][]byte
+	coverage  map[string]glyphSet
+	fontsErr  error
+)
+
+func loadFonts() error {
+	fontsOnce.Do(func() {
+		fontData = map[string][]byte{}
+		coverage = map[string]glyphSet{}
+		for _, face := range fontFaces {
+			data, err := fontFiles.ReadFile(face.file)
+			if err != nil {
+				fontsErr = fmt.Errorf("read font %s: %w", face.file, err)
+				return
+			}
+			glyphs, err := parseCoverage(data)
+			if err != nil {
+				fontsErr = fmt.Errorf("parse font %s: %w", face.file, err)
+				return
+			}
+			fontData[face.file] = data
+			coverage[face.family+face.style] = glyphs
+		}
+	})
+	return fontsErr
+}
+
+func registerFonts(pdf *gofpdf.Fpdf) error {
+	if err := loadFonts(); err != nil {
+		return err
+	}
+	for _, face := range fontFaces {
+		pdf.AddUTF8FontFromBytes(face.family, face.style, bytes.Clone(fontData[face.file]))
+	}
+	return pdf.Error()
+}
+
+func printable(family, style, text string) string {
+	glyphs, ok := coverage[family+style]
+	if !ok {
+		glyphs = coverage[family]
+	}
+	if glyphs == nil {
+		return text
+	}
+
+	covered := true
+	for _, r := range text {
+		if !glyphs[r] && r != '\n' && r != '\t' {
+			covered = false
+			break
+		}
+	}
+	if covered {
+		return text
+	}
+
+	var b strings.Builder
+	for _, r := range text {
+		if glyphs[r] || r == '\n' || r == '\t' {
+			b.WriteRune(r)
+			continue
+		}
+		b.WriteString(fallbackGlyphs(glyphs, r))
+	}
+	return b.String()
+}
+
+func fallbackGlyphs(glyphs glyphSet, r rune) string {
+	decomposed := norm.NFKD.String(string(r))
+	kept := make([]rune, 0, len(decomposed))
+	for _, d := range decomposed {
+		if glyphs[d] {
+			kept = append(kept, d)
+		}
+	}
+	if len(kept) > 0 && decomposed != string(r) {
+		return norm.NFC.String(string(kept))
+	}
+	if glyphs[replacementRune] {
+		return string(replacementRune)
+	}
+	return "?"
+}
+
+func parseCoverage(data []byte) (glyphSet, error) {
+	if len(data) < 12 {
+		return nil, errors.New("truncated font header")
+	}
+
+	numTables := int(binary.BigEndian.Uint16(data[4:]))
+	cmap := -1
+	for i := 0; i < numTables; i++ {
+		record := 12 + 16*i
+		if record+16 > len(data) {
+			return nil, errors.New("truncated table directory")
+		}
+		if string(data[record:record+4]) == "cmap" {
+			cmap = int(binary.BigEndian.Uint32(data[record+8:]))
+			break
+		}
+	}
+	if cmap < 0 || cmap+4 > len(data) {
+		return nil, errors.New("font has no cmap table")
+	}
+
+	subtables := int(binary.BigEndian.Uint16(data[cmap+2:]))
+	for i := 0; i < subtables; i++ {
+		record := cmap + 4 + 8*i
+		if record+8 > len(data) {
+			break
+		}
+		platform := binary.BigEndian.Uint16(data[record:])
+		encoding := binary.BigEndian.Uint16(data[record+2:])
+		offset := cmap + int(binary.BigEndian.Uint32(data[record+4:]))
+		if offset+2 > len(data) || binary.BigEndian.Uint16(data[offset:]) != 4 {
+			continue
+		}
+		if (platform == 3 && encoding == 1) || platform == 0 {
+			return parseCmapFormat4(data, offset)
+		}
+	}
+	return nil, errors.New("font has no unicode cmap (format 4)")
+}
+
+func parseCmapFormat4(data []byte, offset int) (glyphSet, error) {
+	if offset+14 > len(data) {
+		return nil, errors.New("truncated cmap subtable")
+	}
+	segments := int(binary.BigEndian.Uint16(data[offset+6:])) / 2
+	ends := offset + 14
+	starts := ends + 2*segments + 2
+	deltas := starts + 2*segments
+	rangeOffsets := deltas + 2*segments
+	if rangeOffsets+2*segments > len(data) {
+		return nil, errors.New("truncated cmap segments")
+	}
+
+	glyphs := glyphSet{}
+	for seg := 0; seg < segments; seg++ {
+		end := int(binary.BigEndian.Uint16(data[ends+2*seg:]))
+		start := int(binary.BigEndian.Uint16(data[starts+2*seg:]))
+		delta := int(binary.BigEndian.Uint16(data[deltas+2*seg:]))
+		rangePos := rangeOffsets + 2*seg
+		rangeOffset := int(binary.BigEndian.Uint16(data[rangePos:]))
+
+		for c := start; c <= end && c != 0xFFFF; c++ {
+			glyph := 0
+			if rangeOffset == 0 {
+				glyph = (c + delta) & 0xFFFF
+			} else {
+				pos := rangePos + rangeOffset + 2*(c-start)
+				if pos+2 > len(data) {
+					continue
+				}
+				if glyph = int(binary.BigEndian.Uint16(data[pos:])); glyph != 0 {
+					glyph = (glyph + delta) & 0xFFFF
+				}
+			}
+			if glyph != 0 {
+				glyphs[rune(c)] = true
+			}
+		}
+	}
+	return glyphs, nil
+}
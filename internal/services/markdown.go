@@ -0,0 +1,289 @@
+package services
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type mdBlockKind int
+
+const (
+	mdParagraph mdBlockKind = iota
+	mdHeading
+	mdList
+	mdCode
+	mdTable
+	mdQuote
+	mdRule
+)
+
+type mdBlock struct {
+	kind  mdBlockKind
+	level int
+	text  string
+	items []mdListItem
+	rows  [][]string
+}
+
+type mdListItem struct {
+	depth   int
+	ordered bool
+	number  int
+	text    string
+}
+
+type mdSpan struct {
+	text   string
+	bold   bool
+	italic bool
+	code   bool
+}
+
+var (
+	mdHeadingPattern   = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*\s*$`)
+	mdListPattern      = regexp.MustCompile(`^([ \t]*)([-*+]|\d{1,9}[.)])\s+(.*)$`)
+	mdRulePattern      = regexp.MustCompile(`^\s{0,3}([-*_])(\s*[-*_]){2,}\s*$`)
+	mdFencePattern     = regexp.MustCompile("^\\s{0,3}(```+|~~~+)")
+	mdSeparatorPattern = regexp.MustCompile(`^\s*\|?\s*:?-+:?\s*(\|\s*:?-+:?\s*)*\|?\s*$`)
+	mdLinkPattern      = regexp.MustCompile(`\[([^\]]+)\]\([^)]*\)`)
+)
+
+func parseMarkdown(source string) []mdBlock {
+	lines := strings.Split(strings.ReplaceAll(source, "\r\n", "\n"), "\n")
+	blocks := make([]mdBlock, 0)
+	paragraph := make([]string, 0)
+
+	flush := func() {
+		if len(paragraph) > 0 {
+			blocks = append(blocks, mdBlock{kind: mdParagraph, text: strings.Join(paragraph, " ")})
+			paragraph = paragraph[:0]
+		}
+	}
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+
+		if fence := mdFencePattern.FindStringSubmatch(line); fence != nil {
+			flush()
+			code := make([]string, 0)
+			for i++; i < len(lines); i++ {
+				if strings.HasPrefix(strings.TrimSpace(lines[i]), fence[1]) {
+					break
+				}
+				code = append(code, strings.ReplaceAll(lines[i], "\t", "    "))
+			}
+			blocks = append(blocks, mdBlock{kind: mdCode, text: strings.Join(code, "\n")})
+			continue
+		}
+
+		switch {
+		case trimmed == "":
+			flush()
+		case mdHeadingPattern.MatchString(trimmed):
+			flush()
+			match := mdHeadingPattern.FindStringSubmatch(trimmed)
+			blocks = append(blocks, mdBlock{kind: mdHeading, level: len(match[1]), text: match[2]})
+		case mdRulePattern.MatchString(line):
+			flush()
+			blocks = append(blocks, mdBlock{kind: mdRule})
+		case mdListPattern.MatchString(line):
+			flush()
+			block := mdBlock{kind: mdList}
+			for ; i < len(lines); i++ {
+				if match := mdListPattern.FindStringSubmatch(lines[i]); match != nil && !mdRulePattern.MatchString(lines[i]) {
+					block.items = append(block.items, listItem(match))
+					continue
+				}
+				next := strings.TrimSpace(lines[i])
+				if next == "" || indentWidth(lines[i]) == 0 {
+					break
+				}
+				last := &block.items[len(block.items)-1]
+				last.text += " " + next
+			}
+			i--
+			blocks = append(blocks, block)
+		case strings.Contains(trimmed, "|") && i+1 < len(lines) && mdSeparatorPattern.MatchString(lines[i+1]) && strings.Contains(lines[i+1], "-"):
+			flush()
+			block := mdBlock{kind: mdTable, rows: [][]string{tableCells(trimmed)}}
+			for i += 2; i < len(lines); i++ {
+				row := strings.TrimSpace(lines[i])
+				if row == "" || !strings.Contains(row, "|") {
+					break
+				}
+				block.rows = append(block.rows, tableCells(row))
+			}
+			i--
+			blocks = append(blocks, normalizeTable(block))
+		case strings.HasPrefix(trimmed, ">"):
+			flush()
+			quote := make([]string, 0)
+			for ; i < len(lines); i++ {
+				next := strings.TrimSpace(lines[i])
+				if !strings.HasPrefix(next, ">") {
+					break
+				}
+				quote = append(quote, strings.TrimSpace(strings.TrimPrefix(next, ">")))
+			}
+			i--
+			blocks = append(blocks, mdBlock{kind: mdQuote, text: strings.Join(quote, " ")})
+		default:
+			paragraph = append(paragraph, trimmed)
+		}
+	}
+	flush()
+
+	return blocks
+}
+
+func listItem(match []string) mdListItem {
+	item := mdListItem{depth: min(indentWidth(match[1])/2, 4), text: strings.TrimSpace(match[3])}
+	marker := match[2]
+	if n, err := strconv.Atoi(strings.TrimRight(marker, ".)")); err == nil {
+		item.ordered = true
+		item.number = n
+	}
+	return item
+}
+
+func indentWidth(line string) int {
+	width := 0
+	for _, r := range line {
+		switch r {
+		case ' ':
+			width++
+		case '\t':
+			width += 4
+		default:
+			return width
+		}
+	}
+	return width
+}
+
+func tableCells(row string) []string {
+	row = strings.TrimSpace(row)
+	row = strings.TrimPrefix(row, "|")
+	row = strings.TrimSuffix(row, "|")
+
+	cells := make([]string, 0)
+	var cell strings.Builder
+	for i := 0; i < len(row); i++ {
+		switch {
+		case row[i] == '\\' && i+1 < len(row) && row[i+1] == '|':
+			cell.WriteByte('|')
+			i++
+		case row[i] == '|':
+			cells = append(cells, strings.TrimSpace(cell.String()))
+			cell.Reset()
+		default:
+			cell.WriteByte(row[i])
+		}
+	}
+	return append(cells, strings.TrimSpace(cell.String()))
+}
+
+func normalizeTable(block mdBlock) mdBlock {
+	columns := len(block.rows[0])
+	for i, row := range block.rows {
+		for len(row) < columns {
+			row = append(row, "")
+		}
+		block.rows[i] = row[:columns]
+	}
+	return block
+}
+
+func parseInline(text string) []mdSpan {
+	text = mdLinkPattern.ReplaceAllString(text, "$1")
+
+	spans := make([]mdSpan, 0)
+	current := mdSpan{}
+	var buf strings.Builder
+
+	emit := func() {
+		if buf.Len() > 0 {
+			current.text = buf.String()
+			spans = append(spans, current)
+			buf.Reset()
+		}
+	}
+
+	for i := 0; i < len(text); {
+		rest := text[i:]
+		r, size := utf8.DecodeRuneInString(rest)
+
+		switch {
+		case r == '\\' && len(rest) > 1 && strings.ContainsRune("\\`*_[]()#+-.!|", rune(rest[1])):
+			buf.WriteByte(rest[1])
+			i += 2
+			continue
+		case r == '`':
+			if end := strings.IndexByte(rest[1:], '`'); end >= 0 {
+				emit()
+				spans = append(spans, mdSpan{text: rest[1 : end+1], bold: current.bold, italic: current.italic, code: true})
+				i += end + 2
+				continue
+			}
+		case strings.HasPrefix(rest, "**") || strings.HasPrefix(rest, "__"):
+			delim := rest[:2]
+			if canToggle(text, i, len(delim), current.bold) && (current.bold || strings.Contains(rest[2:], delim)) {
+				emit()
+				current.bold = !current.bold
+				i += 2
+				continue
+			}
+			buf.WriteString(delim)
+			i += 2
+			continue
+		case r == '*' || (r == '_' && emphasisBoundary(text, i, current.italic)):
+			delim := string(r)
+			if canToggle(text, i, 1, current.italic) && (current.italic || strings.Contains(rest[1:], delim)) {
+				emit()
+				current.italic = !current.italic
+				i++
+				continue
+			}
+		}
+
+		buf.WriteString(rest[:size])
+		i += size
+	}
+	emit()
+
+	return spans
+}
+
+func canToggle(text string, i, width int, closing bool) bool {
+	if closing {
+		prev, _ := utf8.DecodeLastRuneInString(text[:i])
+		return i > 0 && !unicode.IsSpace(prev)
+	}
+	next, _ := utf8.DecodeRuneInString(text[i+width:])
+	return i+width < len(text) && !unicode.IsSpace(next)
+}
+
+func emphasisBoundary(text string, i int, closing bool) bool {
+	if closing {
+		next, _ := utf8.DecodeRuneInString(text[i+1:])
+		return i+1 >= len(text) || !isWordRune(next)
+	}
+	prev, _ := utf8.DecodeLastRuneInString(text[:i])
+	return i == 0 || !isWordRune(prev)
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func plainInline(text string) string {
+	var b strings.Builder
+	for _, span := range parseInline(text) {
+		b.WriteString(span.text)
+	}
+	return b.String()
+}
@@ -0,0 +1,69 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseMarkdownBlocks(t *testing.T) {
+	source := "# Chapitre 1\n\n" +
+		"Une phrase avec **gras** et\nune *suite*.\n\n" +
+		"- premier\n  - imbriqué\n- second\n  suite du second\n\n" +
+		"1. un\n2) deux\n\n" +
+		"```go\nfunc main() {}\n```\n\n" +
+		"| Terme | Définition |\n|:---|---:|\n| a \\| b | c |\n| seul |\n\n" +
+		"> Citation\n\n---\n"
+
+	blocks := parseMarkdown(source)
+	kinds := make([]mdBlockKind, len(blocks))
+	for i, block := range blocks {
+		kinds[i] = block.kind
+	}
+	want := []mdBlockKind{mdHeading, mdParagraph, mdList, mdList, mdCode, mdTable, mdQuote, mdRule}
+	if !reflect.DeepEqual(kinds, want) {
+		t.Fatalf("unexpected block kinds %v, want %v", kinds, want)
+	}
+
+	if blocks[0].level != 1 || blocks[0].text != "Chapitre 1" {
+		t.Fatalf("unexpected heading %+v", blocks[0])
+	}
+	if blocks[1].text != "Une phrase avec **gras** et une *suite*." {
+		t.Fatalf("unexpected paragraph %q", blocks[1].text)
+	}
+
+	items := blocks[2].items
+	if len(items) != 3 || items[1].depth != 1 || items[2].text != "second suite du second" {
+		t.Fatalf("unexpected bullet items %+v", items)
+	}
+	ordered := blocks[3].items
+	if !ordered[0].ordered || ordered[1].number != 2 {
+		t.Fatalf("unexpected ordered items %+v", ordered)
+	}
+
+	if blocks[4].text != "func main() {}" {
+		t.Fatalf("unexpected code %q", blocks[4].text)
+	}
+	wantRows := [][]string{{"Terme", "Définition"}, {"a | b", "c"}, {"seul", ""}}
+	if !reflect.DeepEqual(blocks[5].rows, wantRows) {
+		t.Fatalf("unexpected table rows %q", blocks[5].rows)
+	}
+}
+
+func TestParseInlineStyles(t *testing.T) {
+	spans := parseInline("Le **théorème *central*** utilise `f(x)`, voir [la doc](http://x) et snake_case_name.")
+	want := []mdSpan{
+		{text: "Le "},
+		{text: "théorème ", bold: true},
+		{text: "central", bold: true, italic: true},
+		{text: " utilise "},
+		{text: "f(x)", code: true},
+		{text: ", voir la doc et snake_case_name."},
+	}
+	if !reflect.DeepEqual(spans, want) {
+		t.Fatalf("unexpected spans:\n got %+v\nwant %+v", spans, want)
+	}
+
+	if got := plainInline("2 * 3 = 6 et un **gras"); got != "2 * 3 = 6 et un **gras" {
+		t.Fatalf("unmatched delimiters should stay literal, got %q", got)
+	}
+}
@@ -1,6 +1,7 @@
 package services
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"encoding/json"
@@ -15,14 +16,19 @@ import (
 	"time"
 
 	"myProfessor/internal/config"
+	"myProfessor/internal/domain"
 )
 
 const (
-	transcriptionEndpoint = "https://api.openai.com/v1/audio/transcriptions"
-	summaryEndpoint       = "https://api.openai.com/v1/chat/completions"
+	transcriptionEndpoint = "/audio/transcriptions"
+	summaryEndpoint       = "/chat/completions"
+	embeddingsEndpoint    = "/embeddings"
 	requestTimeout        = 5 * time.Minute
+	streamIdleTimeout     = time.Minute
 )
 
+var errStreamIdle = errors.New("openai stream idle timeout")
+
 var allowedAudioMIMEs = map[string]struct{}{
 	"audio/webm":  {},
 	"audio/mpeg":  {},
@@ -34,32 +40,78 @@ var allowedAudioMIMEs = map[string]struct{}{
 const summarySystemPrompt = "Tu es un assistant pédagogique. Résume ce cours en bullet points clairs. Sépare Définitions, Concepts, Exemples."
 const courseSystemPrompt = "Tu es un enseignant, transforme cette transcription en un cours complet, clair et structuré, avec titres et sous-parties."
 
+type Transcriber interface {
+	TranscribeAudio(ctx context.Context, path string) (Transcript, error)
+}
+
+type TextGenerator interface {
+	SummarizeText(ctx context.Context, transcription, instructions string) (string, error)
+	GenerateCourse(ctx context.Context, transcription, instructions string) (string, error)
+	StreamCourse(ctx context.Context, transcription, instructions string, onDelta func(string) error) (string, error)
+	AnswerQuestion(ctx context.Context, passages []Passage, history []domain.ChatMessage, question string) (string, error)
+	GenerateQuiz(ctx context.Context, transcription string, questionCount int, instructions string) (string, error)
+	GradeAnswer(ctx context.Context, question, expected, answer string) (float64, string, error)
+	GenerateFlashcards(ctx context.Context, content string, count int) (string, error)
+}
+
+type Embedder interface {
+	Embed(ctx context.Context, inputs []string) ([][]float32, error)
+	EmbeddingModel() string
+}
+
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
 type OpenAIService struct {
 	apiKey          string
+	baseURL         string
 	reqTimeout      time.Duration
+	streamIdle      time.Duration
 	transcribeModel string
 	summaryModel    string
+	embeddingModel  string
+	verboseJSON     bool
+	wordTimestamps  bool
 	httpClient      *http.Client
+	streamClient    *http.Client
 }
 
 func NewOpenAIService(cfg config.Config) *OpenAIService {
+	baseURL := strings.TrimRight(cfg.OpenAIBaseURL, "/")
+	if baseURL == "" {
+		baseURL = config.DefaultOpenAIBaseURL
+	}
+
 	return &OpenAIService{
 		apiKey:          cfg.OpenAIAPIKey,
+		baseURL:         baseURL,
 		reqTimeout:      requestTimeout,
+		streamIdle:      streamIdleTimeout,
 		transcribeModel: cfg.OpenAIModelTranscribe,
 		summaryModel:    cfg.OpenAIModelSummary,
+		embeddingModel:  cfg.OpenAIModelEmbedding,
+		verboseJSON:     cfg.OpenAIVerboseJSON,
+		wordTimestamps:  cfg.OpenAIWordTimestamps,
 		httpClient:      &http.Client{Timeout: requestTimeout},
+		streamClient:    &http.Client{},
 	}
 }
 
 func (s *OpenAIService) Transcribe(r io.Reader, filename string, mime string) (string, error) {
+	transcript, err := s.transcribe(context.Background(), r, filename, mime)
+	return transcript.Text, err
+}
+
+func (s *OpenAIService) transcribe(ctx context.Context, r io.Reader, filename string, mime string) (Transcript, error) {
 	if err := s.ensureAPIKey(); err != nil {
-		return "", err
+		return Transcript{}, err
 	}
 
 	if mime != "" {
 		if _, ok := allowedAudioMIMEs[strings.ToLower(mime)]; !ok {
-			return "", fmt.Errorf("unsupported audio mime type: %s", mime)
+			return Transcript{}, fmt.Errorf("unsupported audio mime type: %s", mime)
 		}
 	}
 
@@ -68,90 +120,117 @@ func (s *OpenAIService) Transcribe(r io.Reader, filename string, mime string) (s
 
 	part, err := writer.CreateFormFile("file", filename)
 	if err != nil {
-		return "", fmt.Errorf("create multipart file: %w", err)
+		return Transcript{}, fmt.Errorf("create multipart file: %w", err)
 	}
 	if _, err := io.Copy(part, r); err != nil {
-		return "", fmt.Errorf("copy audio data: %w", err)
+		return Transcript{}, fmt.Errorf("copy audio data: %w", err)
 	}
 
-	if err := writer.WriteField("model", s.transcribeModel); err != nil {
-		return "", fmt.Errorf("write model field: %w", err)
+	fields := [][2]string{{"model", s.transcribeModel}}
+	if s.verboseJSON {
+		fields = append(fields,
+			[2]string{"response_format", "verbose_json"},
+			[2]string{"timestamp_granularities[]", "segment"},
+		)
+		if s.wordTimestamps {
+			fields = append(fields, [2]string{"timestamp_granularities[]", "word"})
+		}
+	}
+	for _, field := range fields {
+		if err := writer.WriteField(field[0], field[1]); err != nil {
+			return Transcript{}, fmt.Errorf("write %s field: %w", field[0], err)
+		}
 	}
 
 	if err := writer.Close(); err != nil {
-		return "", fmt.Errorf("close multipart writer: %w", err)
+		return Transcript{}, fmt.Errorf("close multipart writer: %w", err)
 	}
 
-	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, transcriptionEndpoint, body)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.baseURL+transcriptionEndpoint, body)
 	if err != nil {
-		return "", fmt.Errorf("create transcription request: %w", err)
+		return Transcript{}, fmt.Errorf("create transcription request: %w", err)
 	}
 
-	req.Header.Set("Authorization", "Bearer "+s.apiKey)
+	s.authorize(req)
 	req.Header.Set("Content-Type", writer.FormDataContentType())
 
 	resp, err := s.do(req)
 	if err != nil {
-		return "", err
+		return Transcript{}, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode >= http.StatusBadRequest {
-		return "", s.decodeAPIError(resp)
+		return Transcript{}, s.decodeAPIError(resp)
 	}
 
 	var payload struct {
-		Text string `json:"text"`
+		Text     string `json:"text"`
+		Segments []struct {
+			Start float64 `json:"start"`
+			End   float64 `json:"end"`
+			Text  string  `json:"text"`
+		} `json:"segments"`
+		Words []struct {
+			Word  string  `json:"word"`
+			Start float64 `json:"start"`
+			End   float64 `json:"end"`
+		} `json:"words"`
 	}
 	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
-		return "", fmt.Errorf("decode transcription response: %w", err)
+		return Transcript{}, fmt.Errorf("decode transcription response: %w", err)
 	}
 
-	return strings.TrimSpace(payload.Text), nil
+	transcript := Transcript{Text: strings.TrimSpace(payload.Text)}
+	for _, segment := range payload.Segments {
+		transcript.Segments = append(transcript.Segments, domain.Segment{
+			Start: segment.Start,
+			End:   segment.End,
+			Text:  strings.TrimSpace(segment.Text),
+		})
+	}
+
+	words := make([]domain.Word, 0, len(payload.Words))
+	for _, word := range payload.Words {
+		words = append(words, domain.Word{Start: word.Start, End: word.End, Text: strings.TrimSpace(word.Word)})
+	}
+	attachWords(transcript.Segments, words)
+
+	return transcript, nil
 }
 
 func (s *OpenAIService) Summarize(transcription string) (string, error) {
-	return s.invokeChatCompletion(summarySystemPrompt, transcription, "")
+	return s.invokeChatCompletion(context.Background(), summarySystemPrompt, transcription, "")
 }
 
-func (s *OpenAIService) GenerateCourse(transcription, instructions string) (string, error) {
-	return s.invokeChatCompletion(courseSystemPrompt, transcription, instructions)
+func (s *OpenAIService) GenerateCourse(ctx context.Context, transcription, instructions string) (string, error) {
+	return s.invokeChatCompletion(ctx, courseSystemPrompt, transcription, instructions)
 }
 
-func (s *OpenAIService) invokeChatCompletion(systemPrompt, transcription, instructions string) (string, error) {
-	if err := s.ensureAPIKey(); err != nil {
-		return "", err
-	}
+func (s *OpenAIService) StreamCourse(ctx context.Context, transcription, instructions string, onDelta func(string) error) (string, error) {
+	return s.streamChatCompletion(ctx, courseSystemPrompt, transcription, instructions, onDelta)
+}
 
-	contentBuilder := strings.Builder{}
-	contentBuilder.WriteString(transcription)
-	if strings.TrimSpace(instructions) != "" {
-		contentBuilder.WriteString("\n\nInstructions supplémentaires :\n")
-		contentBuilder.WriteString(instructions)
-	}
+func (s *OpenAIService) invokeChatCompletion(ctx context.Context, systemPrompt, transcription, instructions string) (string, error) {
+	return s.complete(ctx, promptMessages(systemPrompt, transcription, instructions))
+}
 
-	payload := map[string]any{
-		"model": s.summaryModel,
-		"messages": []map[string]string{
-			{"role": "system", "content": systemPrompt},
-			{"role": "user", "content": contentBuilder.String()},
-		},
-		"temperature": 0.2,
-	}
+func (s *OpenAIService) complete(ctx context.Context, messages []chatMessage) (string, error) {
+	return s.completeWith(ctx, messages, nil)
+}
 
-	buf := &bytes.Buffer{}
-	if err := json.NewEncoder(buf).Encode(payload); err != nil {
-		return "", fmt.Errorf("encode payload: %w", err)
-	}
+func (s *OpenAIService) completeJSON(ctx context.Context, messages []chatMessage) (string, error) {
+	return s.completeWith(ctx, messages, map[string]any{
+		"response_format": map[string]string{"type": "json_object"},
+	})
+}
 
-	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, summaryEndpoint, buf)
+func (s *OpenAIService) completeWith(ctx context.Context, messages []chatMessage, options map[string]any) (string, error) {
+	req, err := s.newChatRequest(ctx, messages, options)
 	if err != nil {
-		return "", fmt.Errorf("create request: %w", err)
+		return "", err
 	}
 
-	req.Header.Set("Authorization", "Bearer "+s.apiKey)
-	req.Header.Set("Content-Type", "application/json")
-
 	resp, err := s.do(req)
 	if err != nil {
 		return "", err
@@ -181,18 +260,186 @@ func (s *OpenAIService) invokeChatCompletion(systemPrompt, transcription, instru
 	return strings.TrimSpace(response.Choices[0].Message.Content), nil
 }
 
-func (s *OpenAIService) TranscribeAudio(ctx context.Context, path string) (string, error) {
+func (s *OpenAIService) streamChatCompletion(ctx context.Context, systemPrompt, transcription, instructions string, onDelta func(string) error) (string, error) {
+	req, err := s.newChatRequest(ctx, promptMessages(systemPrompt, transcription, instructions), map[string]any{"stream": true})
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+
+	resp, err := s.doStream(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return "", s.decodeAPIError(resp)
+	}
+
+	content := strings.Builder{}
+	reader := bufio.NewReader(resp.Body)
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return "", fmt.Errorf("read stream: %w", err)
+		}
+		if errors.Is(err, io.EOF) && line == "" {
+			return "", errors.New("stream ended before completion")
+		}
+
+		line = strings.TrimSpace(line)
+		data, ok := strings.CutPrefix(line, "data:")
+		if !ok {
+			continue
+		}
+		data = strings.TrimSpace(data)
+		if data == "[DONE]" {
+			return strings.TrimSpace(content.String()), nil
+		}
+
+		var chunk struct {
+			Choices []struct {
+				Delta struct {
+					Content string `json:"content"`
+				} `json:"delta"`
+			} `json:"choices"`
+			Error *struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return "", fmt.Errorf("decode stream chunk: %w", err)
+		}
+		if chunk.Error != nil {
+			return "", fmt.Errorf("openai stream error: %s", chunk.Error.Message)
+		}
+
+		for _, choice := range chunk.Choices {
+			if choice.Delta.Content == "" {
+				continue
+			}
+			content.WriteString(choice.Delta.Content)
+			if err := onDelta(choice.Delta.Content); err != nil {
+				return "", err
+			}
+		}
+	}
+}
+
+func promptMessages(systemPrompt, transcription, instructions string) []chatMessage {
+	contentBuilder := strings.Builder{}
+	contentBuilder.WriteString(transcription)
+	if strings.TrimSpace(instructions) != "" {
+		contentBuilder.WriteString("\n\nInstructions supplémentaires :\n")
+		contentBuilder.WriteString(instructions)
+	}
+
+	return []chatMessage{
+		{Role: "system", Content: systemPrompt},
+		{Role: "user", Content: contentBuilder.String()},
+	}
+}
+
+func (s *OpenAIService) newChatRequest(ctx context.Context, messages []chatMessage, options map[string]any) (*http.Request, error) {
+	if err := s.ensureAPIKey(); err != nil {
+		return nil, err
+	}
+
+	payload := map[string]any{
+		"model":       s.summaryModel,
+		"messages":    messages,
+		"temperature": 0.2,
+	}
+	for key, value := range options {
+		payload[key] = value
+	}
+
+	buf := &bytes.Buffer{}
+	if err := json.NewEncoder(buf).Encode(payload); err != nil {
+		return nil, fmt.Errorf("encode payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.baseURL+summaryEndpoint, buf)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+
+	s.authorize(req)
+	req.Header.Set("Content-Type", "application/json")
+	return req, nil
+}
+
+func (s *OpenAIService) TranscribeAudio(ctx context.Context, path string) (Transcript, error) {
 	file, err := os.Open(path)
 	if err != nil {
-		return "", fmt.Errorf("open audio file: %w", err)
+		return Transcript{}, fmt.Errorf("open audio file: %w", err)
 	}
 	defer file.Close()
 
-	return s.Transcribe(file, filepath.Base(path), "")
+	return s.transcribe(ctx, file, filepath.Base(path), "")
+}
+
+func (s *OpenAIService) SummarizeText(ctx context.Context, transcription, instructions string) (string, error) {
+	return s.invokeChatCompletion(ctx, summarySystemPrompt, transcription, instructions)
+}
+
+func (s *OpenAIService) EmbeddingModel() string {
+	return s.embeddingModel
 }
 
-func (s *OpenAIService) SummarizeText(ctx context.Context, transcription string) (string, error) {
-	return s.Summarize(transcription)
+func (s *OpenAIService) Embed(ctx context.Context, inputs []string) ([][]float32, error) {
+	if err := s.ensureAPIKey(); err != nil {
+		return nil, err
+	}
+
+	buf := &bytes.Buffer{}
+	if err := json.NewEncoder(buf).Encode(map[string]any{
+		"model": s.embeddingModel,
+		"input": inputs,
+	}); err != nil {
+		return nil, fmt.Errorf("encode payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.baseURL+embeddingsEndpoint, buf)
+	if err != nil {
+		return nil, fmt.Errorf("create embeddings request: %w", err)
+	}
+
+	s.authorize(req)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return nil, s.decodeAPIError(resp)
+	}
+
+	var response struct {
+		Data []struct {
+			Index     int       `json:"index"`
+			Embedding []float32 `json:"embedding"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("decode embeddings response: %w", err)
+	}
+	if len(response.Data) != len(inputs) {
+		return nil, fmt.Errorf("expected %d embeddings, got %d", len(inputs), len(response.Data))
+	}
+
+	vectors := make([][]float32, len(inputs))
+	for _, item := range response.Data {
+		if item.Index < 0 || item.Index >= len(inputs) {
+			return nil, fmt.Errorf("embedding index %d out of range", item.Index)
+		}
+		vectors[item.Index] = item.Embedding
+	}
+	return vectors, nil
 }
 
 func (s *OpenAIService) do(req *http.Request) (*http.Response, error) {
@@ -200,14 +447,71 @@ func (s *OpenAIService) do(req *http.Request) (*http.Response, error) {
 	req = req.WithContext(ctx)
 
 	resp, err := s.httpClient.Do(req)
-	cancel()
 	if err != nil {
+		cancel()
 		return nil, fmt.Errorf("openai request failed: %w", err)
 	}
 
+	resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
 	return resp, nil
 }
 
+func (s *OpenAIService) doStream(req *http.Request) (*http.Response, error) {
+	ctx, cancel := context.WithCancelCause(req.Context())
+	timer := time.AfterFunc(s.streamIdle, func() { cancel(errStreamIdle) })
+	req = req.WithContext(ctx)
+
+	resp, err := s.streamClient.Do(req)
+	if err != nil {
+		timer.Stop()
+		cancel(nil)
+		if errors.Is(context.Cause(ctx), errStreamIdle) {
+			err = errStreamIdle
+		}
+		return nil, fmt.Errorf("openai request failed: %w", err)
+	}
+
+	resp.Body = &idleTimeoutBody{ReadCloser: resp.Body, ctx: ctx, cancel: cancel, timer: timer, idle: s.streamIdle}
+	return resp, nil
+}
+
+type idleTimeoutBody struct {
+	io.ReadCloser
+	ctx    context.Context
+	cancel context.CancelCauseFunc
+	timer  *time.Timer
+	idle   time.Duration
+}
+
+func (b *idleTimeoutBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if n > 0 {
+		b.timer.Reset(b.idle)
+	}
+	if err != nil && errors.Is(context.Cause(b.ctx), errStreamIdle) {
+		err = errStreamIdle
+	}
+	return n, err
+}
+
+func (b *idleTimeoutBody) Close() error {
+	b.timer.Stop()
+	err := b.ReadCloser.Close()
+	b.cancel(nil)
+	return err
+}
+
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
 func (s *OpenAIService) decodeAPIError(resp *http.Response) error {
 	var apiErr struct {
 		Error struct {
@@ -228,8 +532,14 @@ func (s *OpenAIService) decodeAPIError(resp *http.Response) error {
 	return fmt.Errorf("openai api error: status %d body %s", resp.StatusCode, string(body))
 }
 
+func (s *OpenAIService) authorize(req *http.Request) {
+	if strings.TrimSpace(s.apiKey) != "" {
+		req.Header.Set("Authorization", "Bearer "+s.apiKey)
+	}
+}
+
 func (s *OpenAIService) ensureAPIKey() error {
-	if strings.TrimSpace(s.apiKey) == "" {
+	if strings.TrimSpace(s.apiKey) == "" && s.baseURL == config.DefaultOpenAIBaseURL {
 		return errors.New("openai api key is not configured")
 	}
 	return nil
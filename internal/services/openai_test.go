@@ -0,0 +1,138 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"myProfessor/internal/config"
+)
+
+func TestStreamCourseAssemblesDeltas(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != summaryEndpoint {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, delta := range []string{"# Cours", "\n\n", "Intro"} {
+			fmt.Fprintf(w, "data: {\"choices\":[{\"delta\":{\"content\":%q}}]}\n\n", delta)
+		}
+		fmt.Fprint(w, "data: {\"choices\":[{\"delta\":{}}]}\n\n")
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	defer server.Close()
+
+	svc := NewOpenAIService(config.Config{OpenAIBaseURL: server.URL, OpenAIModelSummary: "local"})
+
+	var deltas []string
+	course, err := svc.StreamCourse(context.Background(), "transcription", "", func(delta string) error {
+		deltas = append(deltas, delta)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("stream course: %v", err)
+	}
+	if len(deltas) != 3 {
+		t.Fatalf("expected 3 deltas, got %d", len(deltas))
+	}
+	if course != "# Cours\n\nIntro" {
+		t.Fatalf("unexpected course %q", course)
+	}
+}
+
+func TestStreamCourseFailsWithoutDone(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "data: {\"choices\":[{\"delta\":{\"content\":\"partiel\"}}]}\n\n")
+	}))
+	defer server.Close()
+
+	svc := NewOpenAIService(config.Config{OpenAIBaseURL: server.URL, OpenAIModelSummary: "local"})
+
+	_, err := svc.StreamCourse(context.Background(), "transcription", "", func(string) error { return nil })
+	if err == nil || !strings.Contains(err.Error(), "stream ended") {
+		t.Fatalf("expected truncated stream error, got %v", err)
+	}
+}
+
+func TestStreamCourseOutlivesRequestTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for i := 0; i < 5; i++ {
+			fmt.Fprintf(w, "data: {\"choices\":[{\"delta\":{\"content\":\"%d\"}}]}\n\n", i)
+			w.(http.Flusher).Flush()
+			time.Sleep(30 * time.Millisecond)
+		}
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	defer server.Close()
+
+	svc := NewOpenAIService(config.Config{OpenAIBaseURL: server.URL, OpenAIModelSummary: "local"})
+	svc.reqTimeout = 50 * time.Millisecond
+	svc.httpClient.Timeout = 50 * time.Millisecond
+	svc.streamIdle = time.Second
+
+	course, err := svc.StreamCourse(context.Background(), "transcription", "", func(string) error { return nil })
+	if err != nil {
+		t.Fatalf("stream course: %v", err)
+	}
+	if course != "01234" {
+		t.Fatalf("unexpected course %q", course)
+	}
+}
+
+func TestStreamCourseFailsWhenStreamStalls(t *testing.T) {
+	stop := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "data: {\"choices\":[{\"delta\":{\"content\":\"partiel\"}}]}\n\n")
+		w.(http.Flusher).Flush()
+		select {
+		case <-stop:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(stop)
+
+	svc := NewOpenAIService(config.Config{OpenAIBaseURL: server.URL, OpenAIModelSummary: "local"})
+	svc.streamIdle = 50 * time.Millisecond
+
+	_, err := svc.StreamCourse(context.Background(), "transcription", "", func(string) error { return nil })
+	if !errors.Is(err, errStreamIdle) {
+		t.Fatalf("expected idle timeout error, got %v", err)
+	}
+}
+
+func TestTranscribeRequestsSegmentsWhenVerboseJSONEnabled(t *testing.T) {
+	var format string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Errorf("parse form: %v", err)
+		}
+		format = r.FormValue("response_format")
+		fmt.Fprint(w, `{"text": "Bonjour.", "segments": [{"start": 0, "end": 1.5, "text": " Bonjour."}]}`)
+	}))
+	defer server.Close()
+
+	for _, verbose := range []bool{true, false} {
+		svc := NewOpenAIService(config.Config{
+			OpenAIBaseURL:         server.URL,
+			OpenAIModelTranscribe: "Systran/faster-whisper-large-v3",
+			OpenAIVerboseJSON:     verbose,
+		})
+
+		transcript, err := svc.Transcribe(strings.NewReader("audio"), "cours.mp3", "")
+		if err != nil {
+			t.Fatalf("transcribe: %v", err)
+		}
+		if transcript != "Bonjour." {
+			t.Fatalf("unexpected transcript %q", transcript)
+		}
+		if want := map[bool]string{true: "verbose_json", false: ""}[verbose]; format != want {
+			t.Fatalf("verbose=%v: expected response_format %q, got %q", verbose, want, format)
+		}
+	}
+}
@@ -4,7 +4,11 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/jung-kurt/gofpdf/v2"
@@ -12,69 +16,413 @@ import (
 	"myProfessor/internal/domain"
 )
 
-type PDFService struct{}
+const (
+	PDFSectionCourse        = "course"
+	PDFSectionSummary       = "summary"
+	PDFSectionTranscription = "transcription"
+
+	bodyFont = "DejaVu"
+	monoFont = "DejaVuMono"
+
+	referenceBodySize = 12.0
+	listIndent        = 6.0
+)
+
+var DefaultPDFSections = []string{PDFSectionCourse, PDFSectionSummary, PDFSectionTranscription}
+
+var headingSizes = map[int]float64{1: 17, 2: 15, 3: 13.5, 4: 12.5}
+
+type PDFOptions struct {
+	Sections []string
+	Template string
+}
+
+func ParsePDFSections(requested []string) ([]string, error) {
+	if len(requested) == 0 {
+		return nil, nil
+	}
+
+	sections := make([]string, 0, len(requested))
+	for _, section := range requested {
+		section = strings.ToLower(strings.TrimSpace(section))
+		if !slices.Contains(DefaultPDFSections, section) {
+			return nil, fmt.Errorf("unknown pdf section %q (expected course, summary or transcription)", section)
+		}
+		if !slices.Contains(sections, section) {
+			sections = append(sections, section)
+		}
+	}
+	return sections, nil
+}
+
+type PDFService struct {
+	mu        sync.RWMutex
+	templates map[string]PDFTemplate
+}
 
 func NewPDFService() *PDFService {
-	return &PDFService{}
+	return &PDFService{templates: map[string]PDFTemplate{DefaultPDFTemplate: defaultPDFTemplate()}}
+}
+
+func (s *PDFService) LoadTemplates(dir string) error {
+	templates, err := LoadPDFTemplates(dir)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.templates = templates
+	s.mu.Unlock()
+	return nil
 }
 
-func (s *PDFService) GeneratePDF(doc domain.Document, folder domain.Folder, outPath string) error {
+func (s *PDFService) Template(name string) (PDFTemplate, error) {
+	if strings.TrimSpace(name) == "" {
+		name = DefaultPDFTemplate
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	tpl, ok := s.templates[name]
+	if !ok {
+		return PDFTemplate{}, fmt.Errorf("unknown pdf template %q", name)
+	}
+	return tpl, nil
+}
+
+func (s *PDFService) Templates() []PDFTemplate {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	templates := make([]PDFTemplate, 0, len(s.templates))
+	for _, tpl := range s.templates {
+		templates = append(templates, tpl)
+	}
+	sort.Slice(templates, func(i, j int) bool {
+		return templates[i].Name < templates[j].Name
+	})
+	return templates
+}
+
+func (s *PDFService) GeneratePDF(doc domain.Document, folder domain.Folder, outPath string, opts PDFOptions) error {
+	tpl, err := s.Template(opts.Template)
+	if err != nil {
+		return err
+	}
+
+	return s.writePDF(outPath, tpl, func(layout pdfLayout) (*pdfDoc, error) {
+		return s.render(doc, folder, tpl, opts.Sections, layout)
+	})
+}
+
+func (s *PDFService) writePDF(outPath string, tpl PDFTemplate, render func(layout pdfLayout) (*pdfDoc, error)) error {
 	if err := os.MkdirAll(filepath.Dir(outPath), 0o755); err != nil {
 		return fmt.Errorf("ensure pdf directory: %w", err)
 	}
 
-	pdf := gofpdf.New("P", "mm", "A4", "")
-	pdf.SetTitle(fmt.Sprintf("Cours %s", doc.ID), false)
-	pdf.SetAuthor("myProfessor", false)
-	pdf.AddPage()
+	pdf, err := render(pdfLayout{})
+	if err != nil {
+		return err
+	}
+	if tpl.countsPages() || pdf.showsTableOfContents() {
+		if pdf, err = render(pdf.layout()); err != nil {
+			return err
+		}
+	}
 
-	title := doc.Title
-	if strings.TrimSpace(title) == "" {
-		title = "Cours"
+	if err := pdf.OutputFileAndClose(outPath); err != nil {
+		return fmt.Errorf("write pdf: %w", err)
 	}
 
+	return nil
+}
+
+func (s *PDFService) render(doc domain.Document, folder domain.Folder, tpl PDFTemplate, requested []string, layout pdfLayout) (*pdfDoc, error) {
+	pdf, err := newPDFDoc(tpl)
+	if err != nil {
+		return nil, err
+	}
+	pdf.SetTitle(fmt.Sprintf("Cours %s", doc.ID), true)
+	pdf.SetAuthor("myProfessor", true)
+
+	title := documentTitle(doc)
 	createdAt := time.Unix(doc.CreatedAt, 0).Local()
+	folderName := folderTitle(folder)
+
+	values := map[string]string{
+		"title":  title,
+		"folder": folderName,
+		"date":   createdAt.Format("02/01/2006"),
+		"pages":  strconv.Itoa(layout.totalPages),
+	}
+	s.setBands(pdf, values)
 
-	pdf.SetFont("Helvetica", "B", 18)
-	pdf.Cell(0, 10, title)
-	pdf.Ln(12)
+	sections := includedSections(doc, requested)
+	pdf.setOutline(documentOutline(doc, sections, 0), layout)
 
-	pdf.SetFont("Helvetica", "", 12)
-	folderLine := "Dossier : Aucun"
-	if folder.ID != "" {
-		folderName := folder.Name
-		if strings.TrimSpace(folderName) == "" {
-			folderName = folder.ID
+	if tpl.Cover.isEnabled() {
+		s.writeCover(pdf, title, expandPlaceholders(tpl.Cover.Subtitle, values), createdAt)
+	}
+	pdf.AddPage()
+	pdf.resetBody()
+
+	if !tpl.Cover.isEnabled() {
+		pdf.SetFont(bodyFont, "B", tpl.Fonts.Title)
+		pdf.setTextColor(tpl.Colors.Title)
+		pdf.Cell(0, tpl.Fonts.Title*0.55, title)
+		pdf.Ln(tpl.Fonts.Title * 0.67)
+
+		pdf.resetBody()
+		folderLine := "Dossier : Aucun"
+		if folderName != "" {
+			folderLine = fmt.Sprintf("Dossier : %s", folderName)
 		}
-		folderLine = fmt.Sprintf("Dossier : %s", folderName)
+		pdf.Cell(0, pdf.lineHeight(), folderLine)
+		pdf.Ln(pdf.lineHeight())
+
+		pdf.Cell(0, pdf.lineHeight(), fmt.Sprintf("Créé le : %s", createdAt.Format("02/01/2006 15:04")))
+		pdf.Ln(2 * pdf.lineHeight())
 	}
-	pdf.Cell(0, 6, folderLine)
-	pdf.Ln(6)
 
-	pdf.Cell(0, 6, fmt.Sprintf("Créé le : %s", createdAt.Format("02/01/2006 15:04")))
-	pdf.Ln(12)
+	if pdf.showsTableOfContents() {
+		s.writeTableOfContents(pdf)
+		pdf.AddPage()
+	}
 
-	s.writeSection(pdf, "Transcription", doc.Transcription, false)
-	pdf.Ln(8)
-	s.writeSection(pdf, "Résumé", doc.Summary, true)
+	s.writeDocumentSections(pdf, doc, sections)
 
-	if err := pdf.OutputFileAndClose(outPath); err != nil {
-		return fmt.Errorf("write pdf: %w", err)
+	if err := pdf.Error(); err != nil {
+		return nil, fmt.Errorf("render pdf: %w", err)
 	}
+	return pdf, nil
+}
 
-	return nil
+func documentTitle(doc domain.Document) string {
+	if strings.TrimSpace(doc.Title) == "" {
+		return "Cours"
+	}
+	return doc.Title
+}
+
+func folderTitle(folder domain.Folder) string {
+	if folder.ID == "" {
+		return ""
+	}
+	if strings.TrimSpace(folder.Name) == "" {
+		return folder.ID
+	}
+	return folder.Name
+}
+
+func (s *PDFService) writeDocumentSections(pdf *pdfDoc, doc domain.Document, sections []string) {
+	for i, section := range sections {
+		if i > 0 {
+			pdf.Ln(8)
+		}
+
+		content := sectionContent(doc, section)
+		switch section {
+		case PDFSectionCourse:
+			s.writeMarkdownSection(pdf, sectionTitle(section), content)
+		case PDFSectionSummary:
+			s.writeSection(pdf, sectionTitle(section), content, true)
+		case PDFSectionTranscription:
+			s.writeSection(pdf, sectionTitle(section), content, false)
+		}
+	}
+}
+
+func (t PDFTemplate) countsPages() bool {
+	return (t.Header.isEnabled() && strings.Contains(t.Header.Text, "{pages}")) ||
+		(t.Footer.isEnabled() && strings.Contains(t.Footer.Text, "{pages}"))
+}
+
+func (s *PDFService) setBands(pdf *pdfDoc, values map[string]string) {
+	tpl := pdf.tpl
+	onCover := func() bool {
+		return pdf.cover && pdf.PageNo() == 1
+	}
+
+	pdf.SetHeaderFunc(func() {
+		if !tpl.Header.isEnabled() || onCover() {
+			return
+		}
+		height := tpl.Fonts.HeaderFooter * 0.5
+		left, top, right, _ := pdf.GetMargins()
+		width, _ := pdf.GetPageSize()
+
+		s.writeBand(pdf, tpl.Header, values, top)
+		pdf.setDrawColor(tpl.Colors.Accent)
+		pdf.Line(left, top+height+1, width-right, top+height+1)
+		pdf.SetDrawColor(0, 0, 0)
+		pdf.SetXY(left, top+height+5)
+	})
+
+	pdf.SetFooterFunc(func() {
+		if !tpl.Footer.isEnabled() || onCover() {
+			return
+		}
+		height := tpl.Fonts.HeaderFooter * 0.5
+		_, _, _, bottom := pdf.GetMargins()
+		_, pageHeight := pdf.GetPageSize()
+
+		s.writeBand(pdf, tpl.Footer, values, pageHeight-bottom/2-height/2)
+	})
+}
+
+func (s *PDFService) writeBand(pdf *pdfDoc, band PDFTemplateBand, values map[string]string, y float64) {
+	family, style := pdf.family, pdf.style
+	defer func() { pdf.family, pdf.style = family, style }()
+
+	values["page"] = strconv.Itoa(pdf.PageNo())
+	align := map[string]string{"left": "L", "center": "C", "right": "R"}[band.Align]
+
+	left, _, _, _ := pdf.GetMargins()
+	pdf.SetFont(bodyFont, "", pdf.tpl.Fonts.HeaderFooter)
+	pdf.setTextColor(pdf.tpl.Colors.Text)
+	pdf.SetXY(left, y)
+	pdf.CellFormat(0, pdf.tpl.Fonts.HeaderFooter*0.5, expandPlaceholders(band.Text, values), "", 0, align, false, 0, "")
+}
+
+func (s *PDFService) writeCover(pdf *pdfDoc, title, subtitle string, createdAt time.Time) {
+	tpl := pdf.tpl
+	pdf.cover = true
+	pdf.AddPage()
+
+	left, _, right, _ := pdf.GetMargins()
+	width, height := pdf.GetPageSize()
+
+	r, g, b := parseColor(tpl.Colors.Accent)
+	pdf.SetFillColor(r, g, b)
+	pdf.Rect(0, 0, width, height*0.04, "F")
+	pdf.SetFillColor(255, 255, 255)
+
+	pdf.SetY(height * 0.35)
+	pdf.SetFont(bodyFont, "B", tpl.Fonts.Title*1.6)
+	pdf.setTextColor(tpl.Colors.Title)
+	pdf.MultiCell(0, tpl.Fonts.Title*0.8, title, "", "C", false)
+
+	pdf.setDrawColor(tpl.Colors.Accent)
+	y := pdf.GetY() + 4
+	pdf.Line(left+(width-left-right)*0.3, y, width-right-(width-left-right)*0.3, y)
+	pdf.SetDrawColor(0, 0, 0)
+	pdf.SetY(y + 6)
+
+	pdf.SetFont(bodyFont, "", tpl.Fonts.Heading)
+	pdf.setTextColor(tpl.Colors.Heading)
+	if strings.TrimSpace(subtitle) != "" {
+		pdf.MultiCell(0, tpl.Fonts.Heading*0.6, subtitle, "", "C", false)
+		pdf.Ln(2)
+	}
+
+	pdf.SetFont(bodyFont, "", tpl.Fonts.Body)
+	pdf.setTextColor(tpl.Colors.Text)
+	pdf.MultiCell(0, pdf.lineHeight(), createdAt.Format("02/01/2006"), "", "C", false)
+}
+
+type pdfDoc struct {
+	*gofpdf.Fpdf
+	tpl    PDFTemplate
+	family string
+	style  string
+	cover  bool
+	toc    []tocEntry
+	placed []int
+}
+
+func newPDFDoc(tpl PDFTemplate) (*pdfDoc, error) {
+	pdf := gofpdf.New(tpl.orientation(), "mm", tpl.PageSize, "")
+	if err := registerFonts(pdf); err != nil {
+		return nil, fmt.Errorf("load pdf fonts: %w", err)
+	}
+	pdf.SetMargins(tpl.Margins.Left, tpl.Margins.Top, tpl.Margins.Right)
+	pdf.SetAutoPageBreak(true, tpl.Margins.Bottom)
+	return &pdfDoc{Fpdf: pdf, tpl: tpl}, nil
+}
+
+func (d *pdfDoc) bodySize() float64 {
+	return d.tpl.Fonts.Body
+}
+
+func (d *pdfDoc) lineHeight() float64 {
+	return d.tpl.Fonts.Body * 0.5
+}
+
+func (d *pdfDoc) scaled(size float64) float64 {
+	return size * d.tpl.Fonts.Body / referenceBodySize
+}
+
+func (d *pdfDoc) resetBody() {
+	d.SetFont(bodyFont, "", d.bodySize())
+	d.setTextColor(d.tpl.Colors.Text)
 }
 
-func (s *PDFService) writeSection(pdf *gofpdf.Fpdf, title, content string, bullet bool) {
-	pdf.SetFont("Helvetica", "B", 14)
-	pdf.Cell(0, 8, title)
-	pdf.Ln(10)
+func (d *pdfDoc) setTextColor(hex string) {
+	d.SetTextColor(parseColor(hex))
+}
+
+func (d *pdfDoc) setDrawColor(hex string) {
+	d.SetDrawColor(parseColor(hex))
+}
+
+func (d *pdfDoc) SetFont(family, style string, size float64) {
+	d.family = family
+	d.style = ""
+	if strings.Contains(strings.ToUpper(style), "B") {
+		d.style += "B"
+	}
+	if strings.Contains(strings.ToUpper(style), "I") {
+		d.style += "I"
+	}
+	d.Fpdf.SetFont(family, d.style, size)
+}
+
+func (d *pdfDoc) text(s string) string {
+	return printable(d.family, d.style, s)
+}
 
-	pdf.SetFont("Helvetica", "", 12)
+func (d *pdfDoc) Cell(w, h float64, txt string) {
+	d.Fpdf.Cell(w, h, d.text(txt))
+}
+
+func (d *pdfDoc) CellFormat(w, h float64, txt, border string, ln int, align string, fill bool, link int, linkURL string) {
+	d.Fpdf.CellFormat(w, h, d.text(txt), border, ln, align, fill, link, linkURL)
+}
+
+func (d *pdfDoc) MultiCell(w, h float64, txt, border, align string, fill bool) {
+	d.Fpdf.MultiCell(w, h, d.text(txt), border, align, fill)
+}
+
+func (d *pdfDoc) Write(h float64, txt string) {
+	d.Fpdf.Write(h, d.text(txt))
+}
+
+func (d *pdfDoc) SplitLines(txt []byte, w float64) [][]byte {
+	return d.Fpdf.SplitLines([]byte(d.text(string(txt))), w)
+}
+
+func (d *pdfDoc) GetStringWidth(s string) float64 {
+	return d.Fpdf.GetStringWidth(d.text(s))
+}
+
+func sectionContent(doc domain.Document, section string) string {
+	switch section {
+	case PDFSectionCourse:
+		return doc.Course
+	case PDFSectionSummary:
+		return doc.Summary
+	case PDFSectionTranscription:
+		return doc.Transcription
+	}
+	return ""
+}
+
+func (s *PDFService) writeSection(pdf *pdfDoc, title, content string, bullet bool) {
+	s.writeSectionTitle(pdf, title)
 
 	lines := strings.Split(strings.TrimSpace(content), "\n")
-	if len(lines) == 0 {
-		pdf.MultiCell(0, 6, "(vide)", "", "L", false)
+	if strings.TrimSpace(content) == "" {
+		pdf.MultiCell(0, pdf.lineHeight(), "(vide)", "", "L", false)
 		return
 	}
 
@@ -88,6 +436,224 @@ func (s *PDFService) writeSection(pdf *gofpdf.Fpdf, title, content string, bulle
 		if bullet {
 			text = fmt.Sprintf("• %s", line)
 		}
-		pdf.MultiCell(0, 6, text, "", "L", false)
+		pdf.MultiCell(0, pdf.lineHeight(), text, "", "L", false)
+	}
+}
+
+func (s *PDFService) writeSectionTitle(pdf *pdfDoc, title string) {
+	size := pdf.tpl.Fonts.Heading
+	pdf.ensureSpace(size*0.71 + 3*pdf.lineHeight())
+	pdf.markEntry()
+	pdf.SetFont(bodyFont, "B", size)
+	pdf.setTextColor(pdf.tpl.Colors.Heading)
+	pdf.Cell(0, size*0.57, title)
+	pdf.Ln(size * 0.71)
+
+	pdf.resetBody()
+}
+
+func (s *PDFService) writeMarkdownSection(pdf *pdfDoc, title, content string) {
+	s.writeSectionTitle(pdf, title)
+
+	blocks := parseMarkdown(content)
+	if len(blocks) == 0 {
+		pdf.MultiCell(0, pdf.lineHeight(), "(vide)", "", "L", false)
+		return
+	}
+
+	for i, block := range blocks {
+		if i > 0 {
+			pdf.Ln(2)
+		}
+
+		switch block.kind {
+		case mdHeading:
+			s.writeHeading(pdf, block)
+		case mdParagraph:
+			s.writeInline(pdf, parseInline(block.text), "", pdf.bodySize(), pdf.lineHeight())
+			pdf.Ln(pdf.lineHeight())
+		case mdList:
+			s.writeList(pdf, block.items)
+		case mdCode:
+			s.writeCode(pdf, block.text)
+		case mdTable:
+			s.writeTable(pdf, block.rows)
+		case mdQuote:
+			s.writeQuote(pdf, block.text)
+		case mdRule:
+			left, _, right, _ := pdf.GetMargins()
+			width, _ := pdf.GetPageSize()
+			y := pdf.GetY() + 2
+			pdf.setDrawColor(pdf.tpl.Colors.Accent)
+			pdf.Line(left, y, width-right, y)
+			pdf.SetDrawColor(0, 0, 0)
+			pdf.Ln(4)
+		}
+	}
+	pdf.resetBody()
+}
+
+func (s *PDFService) writeHeading(pdf *pdfDoc, block mdBlock) {
+	size, ok := headingSizes[block.level]
+	if !ok {
+		size = referenceBodySize
+	}
+	size = pdf.scaled(size)
+	style := "B"
+	if block.level >= 4 {
+		style = "BI"
+	}
+
+	pdf.Ln(2)
+	pdf.ensureSpace(size*0.5 + 1 + 2*pdf.lineHeight())
+	pdf.markEntry()
+	pdf.setTextColor(pdf.tpl.Colors.Heading)
+	s.writeInline(pdf, parseInline(block.text), style, size, size*0.5)
+	pdf.setTextColor(pdf.tpl.Colors.Text)
+	pdf.Ln(size*0.5 + 1)
+}
+
+func (s *PDFService) writeInline(pdf *pdfDoc, spans []mdSpan, baseStyle string, size, lineHeight float64) {
+	for _, span := range spans {
+		family := bodyFont
+		style := baseStyle
+		if span.bold && !strings.Contains(style, "B") {
+			style += "B"
+		}
+		if span.italic && !strings.Contains(style, "I") {
+			style += "I"
+		}
+		if span.code {
+			family = monoFont
+			style = ""
+		}
+
+		pdf.SetFont(family, style, size)
+		pdf.Write(lineHeight, span.text)
+	}
+	pdf.SetFont(bodyFont, "", pdf.bodySize())
+}
+
+func (s *PDFService) writeList(pdf *pdfDoc, items []mdListItem) {
+	left, top, right, _ := pdf.GetMargins()
+	defer pdf.SetMargins(left, top, right)
+
+	for _, item := range items {
+		indent := left + float64(item.depth)*listIndent
+		marker := "•"
+		if item.ordered {
+			marker = fmt.Sprintf("%d.", item.number)
+		}
+
+		pdf.SetFont(bodyFont, "", pdf.bodySize())
+		pdf.SetX(indent)
+		pdf.CellFormat(listIndent, pdf.lineHeight(), marker, "", 0, "L", false, 0, "")
+
+		markerWidth := max(listIndent, pdf.GetStringWidth(marker)+2)
+		pdf.SetLeftMargin(indent + markerWidth)
+		pdf.SetX(indent + markerWidth)
+		s.writeInline(pdf, parseInline(item.text), "", pdf.bodySize(), pdf.lineHeight())
+		pdf.Ln(pdf.lineHeight())
+		pdf.SetLeftMargin(left)
 	}
 }
+
+func (s *PDFService) writeCode(pdf *pdfDoc, code string) {
+	size := pdf.tpl.Fonts.Code
+	pdf.SetFont(monoFont, "", size)
+	pdf.SetFillColor(242, 242, 242)
+	pdf.MultiCell(0, size*0.5, code, "", "L", true)
+	pdf.SetFillColor(255, 255, 255)
+	pdf.SetFont(bodyFont, "", pdf.bodySize())
+}
+
+func (s *PDFService) writeQuote(pdf *pdfDoc, text string) {
+	left, top, right, _ := pdf.GetMargins()
+	pdf.SetLeftMargin(left + listIndent)
+	pdf.SetX(left + listIndent)
+	pdf.SetTextColor(90, 90, 90)
+	s.writeInline(pdf, parseInline(text), "I", pdf.bodySize(), pdf.lineHeight())
+	pdf.Ln(pdf.lineHeight())
+	pdf.setTextColor(pdf.tpl.Colors.Text)
+	pdf.SetMargins(left, top, right)
+}
+
+func (s *PDFService) writeTable(pdf *pdfDoc, rows [][]string) {
+	const padding = 1.5
+	fontSize := pdf.scaled(10)
+	lineHeight := fontSize * 0.5
+
+	left, _, right, bottom := pdf.GetMargins()
+	pageWidth, pageHeight := pdf.GetPageSize()
+	available := pageWidth - left - right
+
+	pdf.SetFont(bodyFont, "", fontSize)
+	columns := len(rows[0])
+	natural := make([]float64, columns)
+	for _, row := range rows {
+		for col, cell := range row {
+			natural[col] = max(natural[col], pdf.GetStringWidth(plainInline(cell))+2*padding)
+		}
+	}
+
+	total := 0.0
+	for _, width := range natural {
+		total += width
+	}
+	widths := make([]float64, columns)
+	for col := range widths {
+		if total <= available {
+			widths[col] = natural[col] * available / total
+		} else {
+			widths[col] = max(available/float64(columns)/2, natural[col]*available/total)
+		}
+	}
+	scale := 0.0
+	for _, width := range widths {
+		scale += width
+	}
+	for col := range widths {
+		widths[col] *= available / scale
+	}
+
+	for r, row := range rows {
+		style := ""
+		if r == 0 {
+			style = "B"
+		}
+		pdf.SetFont(bodyFont, style, fontSize)
+
+		height := lineHeight
+		cells := make([][]string, columns)
+		for col, cell := range row {
+			for _, line := range pdf.SplitLines([]byte(plainInline(cell)), widths[col]-2*padding) {
+				cells[col] = append(cells[col], string(line))
+			}
+			height = max(height, float64(len(cells[col]))*lineHeight)
+		}
+		height += 2 * padding
+
+		if pdf.GetY()+height > pageHeight-bottom {
+			pdf.AddPage()
+		}
+
+		x, y := left, pdf.GetY()
+		for col := range row {
+			if r == 0 {
+				pdf.SetFillColor(230, 230, 230)
+				pdf.Rect(x, y, widths[col], height, "FD")
+			} else {
+				pdf.Rect(x, y, widths[col], height, "D")
+			}
+			for i, line := range cells[col] {
+				pdf.SetXY(x+padding, y+padding+float64(i)*lineHeight)
+				pdf.CellFormat(widths[col]-2*padding, lineHeight, line, "", 0, "L", false, 0, "")
+			}
+			x += widths[col]
+		}
+		pdf.SetXY(left, y+height)
+	}
+
+	pdf.SetFillColor(255, 255, 255)
+	pdf.resetBody()
+}
@@ -0,0 +1,97 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"myProfessor/internal/domain"
+)
+
+func (s *PDFService) GenerateFolderPDF(folder domain.Folder, docs []domain.Document, outPath string, opts PDFOptions) error {
+	if len(docs) == 0 {
+		return errors.New("folder has no documents")
+	}
+	tpl, err := s.Template(opts.Template)
+	if err != nil {
+		return err
+	}
+
+	generatedAt := time.Now()
+	return s.writePDF(outPath, tpl, func(layout pdfLayout) (*pdfDoc, error) {
+		return s.renderBinder(folder, docs, tpl, opts.Sections, generatedAt, layout)
+	})
+}
+
+func (s *PDFService) renderBinder(folder domain.Folder, docs []domain.Document, tpl PDFTemplate, requested []string, generatedAt time.Time, layout pdfLayout) (*pdfDoc, error) {
+	pdf, err := newPDFDoc(tpl)
+	if err != nil {
+		return nil, err
+	}
+
+	name := folderTitle(folder)
+	if name == "" {
+		name = "Classeur"
+	}
+	pdf.SetTitle(name, true)
+	pdf.SetAuthor("myProfessor", true)
+
+	values := map[string]string{
+		"title":  name,
+		"folder": name,
+		"date":   generatedAt.Format("02/01/2006"),
+		"pages":  strconv.Itoa(layout.totalPages),
+	}
+	s.setBands(pdf, values)
+
+	chapters := make([][]string, len(docs))
+	outline := make([]tocEntry, 0)
+	for i, doc := range docs {
+		chapters[i] = includedSections(doc, requested)
+		outline = append(outline, tocEntry{title: chapterTitle(i, doc), level: 0})
+		outline = append(outline, documentOutline(doc, chapters[i], 1)...)
+	}
+	pdf.setOutline(outline, layout)
+
+	s.writeCover(pdf, name, fmt.Sprintf("%d cours", len(docs)), generatedAt)
+	pdf.AddPage()
+	s.writeTableOfContents(pdf)
+
+	for i, doc := range docs {
+		pdf.AddPage()
+		s.writeChapterTitle(pdf, chapterTitle(i, doc), time.Unix(doc.CreatedAt, 0).Local())
+		s.writeDocumentSections(pdf, doc, chapters[i])
+	}
+
+	if err := pdf.Error(); err != nil {
+		return nil, fmt.Errorf("render pdf: %w", err)
+	}
+	return pdf, nil
+}
+
+func chapterTitle(index int, doc domain.Document) string {
+	return fmt.Sprintf("%d. %s", index+1, documentTitle(doc))
+}
+
+func (s *PDFService) writeChapterTitle(pdf *pdfDoc, title string, createdAt time.Time) {
+	tpl := pdf.tpl
+	pdf.markEntry()
+
+	pdf.SetFont(bodyFont, "B", tpl.Fonts.Title)
+	pdf.setTextColor(tpl.Colors.Title)
+	pdf.MultiCell(0, tpl.Fonts.Title*0.55, title, "", "L", false)
+	pdf.Ln(1)
+
+	pdf.resetBody()
+	pdf.Cell(0, pdf.lineHeight(), fmt.Sprintf("Créé le : %s", createdAt.Format("02/01/2006 15:04")))
+	pdf.Ln(pdf.lineHeight())
+
+	left, _, right, _ := pdf.GetMargins()
+	width, _ := pdf.GetPageSize()
+	y := pdf.GetY() + 2
+	pdf.setDrawColor(tpl.Colors.Accent)
+	pdf.Line(left, y, width-right, y)
+	pdf.SetDrawColor(0, 0, 0)
+	pdf.Ln(8)
+}
@@ -0,0 +1,47 @@
+package services
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"myProfessor/internal/domain"
+)
+
+func TestGenerateFolderPDF(t *testing.T) {
+	docs := []domain.Document{
+		{ID: "a", Title: "Amphi 1", CreatedAt: 1, Course: "# Suites\n\nDéfinitions.", Summary: "Convergence"},
+		{ID: "b", Title: "Amphi 2", CreatedAt: 2, Course: "# Séries\n\n## Critères\n\nComparaison.", Transcription: "Bonjour."},
+	}
+	path := filepath.Join(t.TempDir(), "binder.pdf")
+	svc := NewPDFService()
+
+	if err := svc.GenerateFolderPDF(domain.Folder{ID: "f", Name: "Analyse"}, docs, path, PDFOptions{}); err != nil {
+		t.Fatalf("generate folder pdf: %v", err)
+	}
+
+	pages := extractPDFPages(t, path)
+	if len(pages) != 4 {
+		t.Fatalf("expected cover, contents and one page per lecture, got %d pages", len(pages))
+	}
+	if !strings.Contains(pages[0], "Analyse") || !strings.Contains(pages[0], "2 cours") {
+		t.Errorf("unexpected cover:\n%s", pages[0])
+	}
+
+	toc := strings.Split(pages[1], "\n")
+	for title, page := range map[string]int{"1. Amphi 1": 3, "Suites": 3, "2. Amphi 2": 4, "Séries": 4, "Transcription": 4} {
+		if got := tocPage(toc, title); got != page {
+			t.Errorf("expected %q on page %d in the table of contents, got %d:\n%s", title, page, got, pages[1])
+		}
+	}
+	if strings.Contains(pages[1], "Critères") {
+		t.Error("headings below the table of contents depth should only appear in the outline")
+	}
+	if !strings.Contains(pages[3], "2. Amphi 2") || !strings.Contains(pages[3], "Critères") {
+		t.Errorf("unexpected second chapter:\n%s", pages[3])
+	}
+
+	if err := svc.GenerateFolderPDF(domain.Folder{ID: "f"}, nil, path, PDFOptions{}); err == nil {
+		t.Fatal("expected an error for an empty folder")
+	}
+}
@@ -0,0 +1,290 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const DefaultPDFTemplate = "default"
+
+var (
+	templateNamePattern  = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+	templateColorPattern = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+	placeholderPattern   = regexp.MustCompile(`\{([^{}]*)\}`)
+
+	templatePlaceholders = []string{"title", "folder", "date", "page", "pages"}
+	templatePageSizes    = []string{"A4", "A5", "Letter", "Legal"}
+)
+
+type PDFTemplate struct {
+	Name        string             `json:"name" yaml:"name"`
+	Description string             `json:"description,omitempty" yaml:"description"`
+	PageSize    string             `json:"pageSize" yaml:"pageSize"`
+	Orientation string             `json:"orientation" yaml:"orientation"`
+	Margins     PDFTemplateMargins `json:"margins" yaml:"margins"`
+	Fonts       PDFTemplateFonts   `json:"fonts" yaml:"fonts"`
+	Colors      PDFTemplateColors  `json:"colors" yaml:"colors"`
+	Cover       PDFTemplateCover   `json:"cover" yaml:"cover"`
+	Header      PDFTemplateBand    `json:"header" yaml:"header"`
+	Footer      PDFTemplateBand    `json:"footer" yaml:"footer"`
+}
+
+type PDFTemplateMargins struct {
+	Top    float64 `json:"top" yaml:"top"`
+	Right  float64 `json:"right" yaml:"right"`
+	Bottom float64 `json:"bottom" yaml:"bottom"`
+	Left   float64 `json:"left" yaml:"left"`
+}
+
+type PDFTemplateFonts struct {
+	Title        float64 `json:"title" yaml:"title"`
+	Heading      float64 `json:"heading" yaml:"heading"`
+	Body         float64 `json:"body" yaml:"body"`
+	Code         float64 `json:"code" yaml:"code"`
+	HeaderFooter float64 `json:"headerFooter" yaml:"headerFooter"`
+}
+
+type PDFTemplateColors struct {
+	Title   string `json:"title" yaml:"title"`
+	Heading string `json:"heading" yaml:"heading"`
+	Text    string `json:"text" yaml:"text"`
+	Accent  string `json:"accent" yaml:"accent"`
+}
+
+type PDFTemplateCover struct {
+	Enabled  *bool  `json:"enabled" yaml:"enabled"`
+	Subtitle string `json:"subtitle,omitempty" yaml:"subtitle"`
+}
+
+type PDFTemplateBand struct {
+	Enabled *bool  `json:"enabled" yaml:"enabled"`
+	Text    string `json:"text,omitempty" yaml:"text"`
+	Align   string `json:"align,omitempty" yaml:"align"`
+}
+
+func (c PDFTemplateCover) isEnabled() bool {
+	return c.Enabled != nil && *c.Enabled
+}
+
+func (b PDFTemplateBand) isEnabled() bool {
+	return b.Enabled != nil && *b.Enabled
+}
+
+func defaultPDFTemplate() PDFTemplate {
+	return PDFTemplate{
+		Name:        DefaultPDFTemplate,
+		Description: "Mise en page standard",
+		PageSize:    "A4",
+		Orientation: "portrait",
+		Margins:     PDFTemplateMargins{Top: 10, Right: 10, Bottom: 20, Left: 10},
+		Fonts:       PDFTemplateFonts{Title: 18, Heading: 14, Body: 12, Code: 10, HeaderFooter: 9},
+		Colors:      PDFTemplateColors{Title: "#000000", Heading: "#000000", Text: "#000000", Accent: "#b4b4b4"},
+		Cover:       PDFTemplateCover{Enabled: new(bool), Subtitle: "{folder}"},
+		Header:      PDFTemplateBand{Enabled: new(bool), Text: "{folder}", Align: "left"},
+		Footer:      PDFTemplateBand{Enabled: new(bool), Text: "page {page} / {pages}", Align: "center"},
+	}
+}
+
+func (t PDFTemplate) withDefaults(base PDFTemplate) PDFTemplate {
+	orString := func(value, fallback string) string {
+		if strings.TrimSpace(value) == "" {
+			return fallback
+		}
+		return value
+	}
+	orFloat := func(value, fallback float64) float64 {
+		if value == 0 {
+			return fallback
+		}
+		return value
+	}
+	orBool := func(value, fallback *bool) *bool {
+		if value == nil {
+			return fallback
+		}
+		return value
+	}
+
+	t.PageSize = orString(t.PageSize, base.PageSize)
+	t.Orientation = orString(t.Orientation, base.Orientation)
+	t.Margins.Top = orFloat(t.Margins.Top, base.Margins.Top)
+	t.Margins.Right = orFloat(t.Margins.Right, base.Margins.Right)
+	t.Margins.Bottom = orFloat(t.Margins.Bottom, base.Margins.Bottom)
+	t.Margins.Left = orFloat(t.Margins.Left, base.Margins.Left)
+	t.Fonts.Title = orFloat(t.Fonts.Title, base.Fonts.Title)
+	t.Fonts.Heading = orFloat(t.Fonts.Heading, base.Fonts.Heading)
+	t.Fonts.Body = orFloat(t.Fonts.Body, base.Fonts.Body)
+	t.Fonts.Code = orFloat(t.Fonts.Code, base.Fonts.Code)
+	t.Fonts.HeaderFooter = orFloat(t.Fonts.HeaderFooter, base.Fonts.HeaderFooter)
+	t.Colors.Title = orString(t.Colors.Title, base.Colors.Title)
+	t.Colors.Heading = orString(t.Colors.Heading, base.Colors.Heading)
+	t.Colors.Text = orString(t.Colors.Text, base.Colors.Text)
+	t.Colors.Accent = orString(t.Colors.Accent, base.Colors.Accent)
+	t.Cover.Enabled = orBool(t.Cover.Enabled, base.Cover.Enabled)
+	t.Cover.Subtitle = orString(t.Cover.Subtitle, base.Cover.Subtitle)
+	t.Header.Enabled = orBool(t.Header.Enabled, base.Header.Enabled)
+	t.Header.Text = orString(t.Header.Text, base.Header.Text)
+	t.Header.Align = orString(t.Header.Align, base.Header.Align)
+	t.Footer.Enabled = orBool(t.Footer.Enabled, base.Footer.Enabled)
+	t.Footer.Text = orString(t.Footer.Text, base.Footer.Text)
+	t.Footer.Align = orString(t.Footer.Align, base.Footer.Align)
+	return t
+}
+
+func (t PDFTemplate) Validate() error {
+	problems := make([]string, 0)
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			problems = append(problems, fmt.Sprintf(format, args...))
+		}
+	}
+
+	check(templateNamePattern.MatchString(t.Name), "name %q must use lowercase letters, digits, '-' or '_'", t.Name)
+	check(slices.Contains(templatePageSizes, t.PageSize), "pageSize %q must be one of %s", t.PageSize, strings.Join(templatePageSizes, ", "))
+	check(t.Orientation == "portrait" || t.Orientation == "landscape", "orientation %q must be portrait or landscape", t.Orientation)
+
+	margins := map[string]float64{"top": t.Margins.Top, "right": t.Margins.Right, "bottom": t.Margins.Bottom, "left": t.Margins.Left}
+	for _, side := range []string{"top", "right", "bottom", "left"} {
+		check(margins[side] >= 5 && margins[side] <= 60, "margins.%s %.1f must be between 5 and 60 mm", side, margins[side])
+	}
+
+	fonts := map[string]float64{"title": t.Fonts.Title, "heading": t.Fonts.Heading, "body": t.Fonts.Body, "code": t.Fonts.Code, "headerFooter": t.Fonts.HeaderFooter}
+	for _, name := range []string{"title", "heading", "body", "code", "headerFooter"} {
+		check(fonts[name] >= 6 && fonts[name] <= 48, "fonts.%s %.1f must be between 6 and 48 pt", name, fonts[name])
+	}
+
+	colors := map[string]string{"title": t.Colors.Title, "heading": t.Colors.Heading, "text": t.Colors.Text, "accent": t.Colors.Accent}
+	for _, name := range []string{"title", "heading", "text", "accent"} {
+		check(templateColorPattern.MatchString(colors[name]), "colors.%s %q must be a #RRGGBB color", name, colors[name])
+	}
+
+	for field, band := range map[string]PDFTemplateBand{"header": t.Header, "footer": t.Footer} {
+		check(band.Align == "left" || band.Align == "center" || band.Align == "right", "%s.align %q must be left, center or right", field, band.Align)
+	}
+
+	texts := map[string]string{"cover.subtitle": t.Cover.Subtitle, "header.text": t.Header.Text, "footer.text": t.Footer.Text}
+	for _, field := range []string{"cover.subtitle", "header.text", "footer.text"} {
+		for _, match := range placeholderPattern.FindAllStringSubmatch(texts[field], -1) {
+			check(slices.Contains(templatePlaceholders, match[1]), "%s uses unknown placeholder {%s}", field, match[1])
+		}
+	}
+
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return errors.New(strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+func expandPlaceholders(text string, values map[string]string) string {
+	return placeholderPattern.ReplaceAllStringFunc(text, func(match string) string {
+		if value, ok := values[match[1:len(match)-1]]; ok {
+			return value
+		}
+		return match
+	})
+}
+
+func (t PDFTemplate) orientation() string {
+	if t.Orientation == "landscape" {
+		return "L"
+	}
+	return "P"
+}
+
+func parseColor(hex string) (int, int, int) {
+	value, err := strconv.ParseUint(strings.TrimPrefix(hex, "#"), 16, 32)
+	if err != nil {
+		return 0, 0, 0
+	}
+	return int(value >> 16 & 0xff), int(value >> 8 & 0xff), int(value & 0xff)
+}
+
+func LoadPDFTemplates(dir string) (map[string]PDFTemplate, error) {
+	base := defaultPDFTemplate()
+	templates := map[string]PDFTemplate{}
+	sources := map[string]string{}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("read template directory: %w", err)
+	}
+
+	for _, entry := range entries {
+		ext := strings.ToLower(filepath.Ext(entry.Name()))
+		if entry.IsDir() || (ext != ".json" && ext != ".yaml" && ext != ".yml") {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+
+		tpl, err := readPDFTemplate(path)
+		if err != nil {
+			return nil, fmt.Errorf("template %s: %w", entry.Name(), err)
+		}
+		if tpl.Name == "" {
+			tpl.Name = strings.TrimSuffix(entry.Name(), filepath.Ext(entry.Name()))
+		}
+		if previous, ok := sources[tpl.Name]; ok {
+			return nil, fmt.Errorf("template %s: name %q is already defined by %s", entry.Name(), tpl.Name, previous)
+		}
+		sources[tpl.Name] = entry.Name()
+		templates[tpl.Name] = tpl
+	}
+
+	if custom, ok := templates[DefaultPDFTemplate]; ok {
+		base = custom.withDefaults(base)
+		if err := base.Validate(); err != nil {
+			return nil, fmt.Errorf("template %s: %w", sources[DefaultPDFTemplate], err)
+		}
+	}
+	templates[DefaultPDFTemplate] = base
+
+	for name, tpl := range templates {
+		if name == DefaultPDFTemplate {
+			continue
+		}
+		tpl = tpl.withDefaults(base)
+		if err := tpl.Validate(); err != nil {
+			return nil, fmt.Errorf("template %s: %w", sources[name], err)
+		}
+		templates[name] = tpl
+	}
+
+	return templates, nil
+}
+
+func readPDFTemplate(path string) (PDFTemplate, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return PDFTemplate{}, fmt.Errorf("read: %w", err)
+	}
+
+	var tpl PDFTemplate
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&tpl); err != nil {
+			return PDFTemplate{}, fmt.Errorf("invalid json: %w", err)
+		}
+		return tpl, nil
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&tpl); err != nil && !errors.Is(err, io.EOF) {
+		return PDFTemplate{}, fmt.Errorf("invalid yaml: %w", err)
+	}
+	return tpl, nil
+}
@@ -0,0 +1,141 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"myProfessor/internal/domain"
+)
+
+func writeTemplate(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+		t.Fatalf("write template: %v", err)
+	}
+}
+
+func TestLoadPDFTemplates(t *testing.T) {
+	dir := t.TempDir()
+	writeTemplate(t, dir, "sobre.json", `{"description": "Sobre", "fonts": {"body": 11}, "footer": {"enabled": true}}`)
+	writeTemplate(t, dir, "examen.yaml", "name: examen\npageSize: A5\norientation: landscape\ncolors:\n  heading: \"#1f4e79\"\ncover:\n  enabled: true\n  subtitle: \"{folder} - {date}\"\nheader:\n  enabled: true\n  align: right\n")
+	writeTemplate(t, dir, "notes.txt", "ignored")
+
+	templates, err := LoadPDFTemplates(dir)
+	if err != nil {
+		t.Fatalf("load templates: %v", err)
+	}
+	if len(templates) != 3 {
+		t.Fatalf("expected default, sobre and examen, got %v", templates)
+	}
+
+	sobre := templates["sobre"]
+	if sobre.Fonts.Body != 11 || sobre.Fonts.Title != 18 || sobre.Footer.Text != "page {page} / {pages}" || sobre.PageSize != "A4" {
+		t.Fatalf("expected defaults to fill the sobre template, got %+v", sobre)
+	}
+	examen := templates["examen"]
+	if examen.PageSize != "A5" || examen.Colors.Heading != "#1f4e79" || !examen.Cover.isEnabled() || examen.Header.Align != "right" {
+		t.Fatalf("unexpected examen template %+v", examen)
+	}
+
+	if templates, err := LoadPDFTemplates(filepath.Join(dir, "missing")); err != nil || len(templates) != 1 {
+		t.Fatalf("expected only the default template without a directory, got %v (%v)", templates, err)
+	}
+}
+
+func TestLoadPDFTemplatesInheritsEnabledFlags(t *testing.T) {
+	dir := t.TempDir()
+	writeTemplate(t, dir, "default.yaml", "cover:\n  enabled: true\nfooter:\n  enabled: true\n")
+	writeTemplate(t, dir, "sobre.json", `{"footer": {"enabled": false}, "header": {"enabled": true}}`)
+	writeTemplate(t, dir, "cours.json", `{"fonts": {"body": 11}}`)
+
+	templates, err := LoadPDFTemplates(dir)
+	if err != nil {
+		t.Fatalf("load templates: %v", err)
+	}
+
+	for name, want := range map[string][3]bool{
+		"default": {true, false, true},
+		"cours":   {true, false, true},
+		"sobre":   {true, true, false},
+	} {
+		tpl := templates[name]
+		got := [3]bool{tpl.Cover.isEnabled(), tpl.Header.isEnabled(), tpl.Footer.isEnabled()}
+		if got != want {
+			t.Errorf("%s: expected cover/header/footer %v, got %v", name, want, got)
+		}
+	}
+}
+
+func TestLoadPDFTemplatesRejectsInvalidFiles(t *testing.T) {
+	cases := map[string]struct {
+		file    string
+		content string
+		want    string
+	}{
+		"unknown field":       {"a.json", `{"colours": {}}`, "unknown field"},
+		"unknown yaml field":  {"a.yaml", "margin: 10\n", "field margin not found"},
+		"bad color":           {"a.json", `{"colors": {"accent": "blue"}}`, "colors.accent"},
+		"bad page size":       {"a.yml", "pageSize: A3\n", "pageSize"},
+		"margin out of range": {"a.json", `{"margins": {"left": 200}}`, "margins.left"},
+		"unknown placeholder": {"a.json", `{"footer": {"enabled": true, "text": "{page} sur {total}"}}`, "{total}"},
+		"bad name":            {"a.json", `{"name": "Mon Modèle"}`, "name"},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeTemplate(t, dir, tc.file, tc.content)
+
+			_, err := LoadPDFTemplates(dir)
+			if err == nil {
+				t.Fatal("expected a validation error")
+			}
+			if !strings.Contains(err.Error(), tc.file) || !strings.Contains(err.Error(), tc.want) {
+				t.Fatalf("expected error naming %s and %q, got %v", tc.file, tc.want, err)
+			}
+		})
+	}
+
+	dir := t.TempDir()
+	writeTemplate(t, dir, "a.json", `{"name": "cours"}`)
+	writeTemplate(t, dir, "b.yaml", "name: cours\n")
+	if _, err := LoadPDFTemplates(dir); err == nil || !strings.Contains(err.Error(), "already defined") {
+		t.Fatalf("expected a duplicate name error, got %v", err)
+	}
+}
+
+func TestGeneratePDFAppliesTemplate(t *testing.T) {
+	dir := t.TempDir()
+	writeTemplate(t, dir, "examen.json", `{
+		"cover": {"enabled": true, "subtitle": "Dossier {folder}"},
+		"header": {"enabled": true},
+		"footer": {"enabled": true}
+	}`)
+
+	svc := NewPDFService()
+	if err := svc.LoadTemplates(dir); err != nil {
+		t.Fatalf("load templates: %v", err)
+	}
+
+	doc := domain.Document{ID: "doc-3", Title: "Amphi 4", Course: sampleCourse, Transcription: "Bonjour."}
+	path := filepath.Join(t.TempDir(), "templated.pdf")
+	if err := svc.GeneratePDF(doc, domain.Folder{ID: "f", Name: "Maths"}, path, PDFOptions{Template: "examen"}); err != nil {
+		t.Fatalf("generate pdf: %v", err)
+	}
+
+	text := extractPDFText(t, path)
+	for _, want := range []string{"Amphi 4", "Dossier Maths", "page 3 / 3"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected %q in extracted text:\n%s", want, text)
+		}
+	}
+	if strings.Contains(text, "page 1 / 3") {
+		t.Error("the cover page should not carry a footer")
+	}
+
+	if err := svc.GeneratePDF(doc, domain.Folder{}, path, PDFOptions{Template: "absent"}); err == nil {
+		t.Fatal("expected an error for an unknown template")
+	}
+}
@@ -0,0 +1,201 @@
+package services
+
+import (
+	"bytes"
+	"compress/zlib"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"unicode/utf16"
+
+	"myProfessor/internal/domain"
+)
+
+const sampleCourse = `# Algèbre linéaire
+
+## 1. Valeurs propres
+
+Un **vecteur propre** v vérifie *Av = λv*, voir ` + "`eig(A)`" + `.
+
+- Polynôme caractéristique
+  - det(A - λI) = 0
+- Diagonalisation
+
+1. Calculer le polynôme
+2. Trouver les racines
+
+` + "```" + `
+A = [[2, 0], [0, 3]]
+` + "```" + `
+
+| Notion | Définition |
+|---|---|
+| Trace | Somme des coefficients diagonaux, égale à la somme des valeurs propres |
+| Déterminant | Produit des valeurs propres |
+
+> À retenir pour l'examen.
+
+---
+`
+
+func TestGeneratePDFRendersSelectedSections(t *testing.T) {
+	doc := domain.Document{
+		ID:            "doc-1",
+		Title:         "Amphi 3",
+		Transcription: "Bonjour à tous.",
+		Summary:       "Valeurs propres\nDiagonalisation",
+		Course:        sampleCourse,
+	}
+	svc := NewPDFService()
+	dir := t.TempDir()
+
+	full := filepath.Join(dir, "full.pdf")
+	if err := svc.GeneratePDF(doc, domain.Folder{ID: "f", Name: "Maths"}, full, PDFOptions{}); err != nil {
+		t.Fatalf("generate pdf: %v", err)
+	}
+	summaryOnly := filepath.Join(dir, "summary.pdf")
+	if err := svc.GeneratePDF(doc, domain.Folder{}, summaryOnly, PDFOptions{Sections: []string{PDFSectionSummary}}); err != nil {
+		t.Fatalf("generate pdf: %v", err)
+	}
+
+	for _, path := range []string{full, summaryOnly} {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("read pdf: %v", err)
+		}
+		if !bytes.HasPrefix(data, []byte("%PDF-")) {
+			t.Fatalf("%s is not a pdf", path)
+		}
+	}
+
+	if _, err := ParsePDFSections([]string{"course", "annexes"}); err == nil {
+		t.Fatal("expected an error for an unknown section")
+	}
+	sections, err := ParsePDFSections([]string{" Course", "summary", "course"})
+	if err != nil || len(sections) != 2 || sections[0] != PDFSectionCourse {
+		t.Fatalf("unexpected sections %v (%v)", sections, err)
+	}
+}
+
+func TestGeneratePDFKeepsUnicodeText(t *testing.T) {
+	doc := domain.Document{
+		ID:            "doc-2",
+		Title:         "Résumé de thermodynamique",
+		Summary:       "Énergie interne ΔU = Q − W\nÀ retenir : ∑ Fᵢ ≥ 0",
+		Course:        "## Équations\n\n- **Opérateur** ∇ et intégrale ∫ f(x) dx ≈ π\n- *Entropie* λ, µ, ∞\n\nVariable 𝑥 et smiley 😀 ou 漢.",
+		Transcription: "Bonjour à tous, ça va ? Œuvre naïve « citée ».",
+	}
+	path := filepath.Join(t.TempDir(), "unicode.pdf")
+	if err := NewPDFService().GeneratePDF(doc, domain.Folder{ID: "f", Name: "Génie thermique"}, path, PDFOptions{}); err != nil {
+		t.Fatalf("generate pdf: %v", err)
+	}
+
+	text := extractPDFText(t, path)
+	for _, want := range []string{
+		"Résumé de thermodynamique",
+		"Dossier : Génie thermique",
+		"Créé le : ",
+		"• Énergie interne ΔU = Q − W",
+		"∑ Fᵢ ≥ 0",
+		"Équations",
+		"Opérateur",
+		"∇ et intégrale ∫ f(x) dx ≈ π",
+		"λ, µ, ∞",
+		"Bonjour à tous, ça va ? Œuvre naïve « citée ».",
+		"Variable x et smiley � ou �.",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected %q in extracted text:\n%s", want, text)
+		}
+	}
+	if strings.Contains(text, "😀") {
+		t.Error("glyphs outside the font should be replaced")
+	}
+}
+
+var pdfTextPattern = regexp.MustCompile(`\(((?:\\.|[^\\)])*)\)\s*Tj`)
+
+func extractPDFText(t *testing.T, path string) string {
+	t.Helper()
+	return strings.Join(extractPDFPages(t, path), "\n")
+}
+
+func extractPDFPages(t *testing.T, path string) []string {
+	t.Helper()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read pdf: %v", err)
+	}
+
+	var pages []string
+	for {
+		start := bytes.Index(data, []byte("stream\n"))
+		if start < 0 {
+			break
+		}
+		data = data[start+len("stream\n"):]
+		end := bytes.Index(data, []byte("endstream"))
+		if end < 0 {
+			break
+		}
+		stream := data[:end]
+		data = data[end+len("endstream"):]
+
+		reader, err := zlib.NewReader(bytes.NewReader(stream))
+		if err != nil {
+			continue
+		}
+		content, err := io.ReadAll(reader)
+		if err != nil {
+			continue
+		}
+		var lines []string
+		for _, match := range pdfTextPattern.FindAllSubmatch(content, -1) {
+			lines = append(lines, decodePDFString(match[1]))
+		}
+		if len(lines) > 0 {
+			pages = append(pages, strings.Join(lines, "\n"))
+		}
+	}
+	return pages
+}
+
+func decodePDFString(raw []byte) string {
+	unescaped := make([]byte, 0, len(raw))
+	for i := 0; i < len(raw); i++ {
+		if raw[i] != '\\' || i+1 == len(raw) {
+			unescaped = append(unescaped, raw[i])
+			continue
+		}
+		i++
+		switch raw[i] {
+		case 'n':
+			unescaped = append(unescaped, '\n')
+		case 'r':
+			unescaped = append(unescaped, '\r')
+		case 't':
+			unescaped = append(unescaped, '\t')
+		default:
+			if raw[i] >= '0' && raw[i] <= '7' {
+				value, n := 0, 0
+				for ; n < 3 && i+n < len(raw) && raw[i+n] >= '0' && raw[i+n] <= '7'; n++ {
+					value = value*8 + int(raw[i+n]-'0')
+				}
+				unescaped = append(unescaped, byte(value))
+				i += n - 1
+				continue
+			}
+			unescaped = append(unescaped, raw[i])
+		}
+	}
+
+	units := make([]uint16, 0, len(unescaped)/2)
+	for i := 0; i+1 < len(unescaped); i += 2 {
+		units = append(units, uint16(unescaped[i])<<8|uint16(unescaped[i+1]))
+	}
+	return string(utf16.Decode(units))
+}
@@ -0,0 +1,180 @@
+package services
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	"myProfessor/internal/domain"
+)
+
+const (
+	tocMaxLevel    = 2
+	tocMinEntries  = 2
+	tocNumberWidth = 12.0
+)
+
+type tocEntry struct {
+	title string
+	level int
+	link  int
+	page  int
+}
+
+type pdfLayout struct {
+	totalPages int
+	pages      []int
+}
+
+func sectionTitle(section string) string {
+	switch section {
+	case PDFSectionCourse:
+		return "Cours"
+	case PDFSectionSummary:
+		return "Résumé"
+	case PDFSectionTranscription:
+		return "Transcription"
+	}
+	return section
+}
+
+func includedSections(doc domain.Document, requested []string) []string {
+	if len(requested) > 0 {
+		return requested
+	}
+
+	sections := make([]string, 0, len(DefaultPDFSections))
+	for _, section := range DefaultPDFSections {
+		if strings.TrimSpace(sectionContent(doc, section)) != "" {
+			sections = append(sections, section)
+		}
+	}
+	return sections
+}
+
+func documentOutline(doc domain.Document, sections []string, base int) []tocEntry {
+	entries := make([]tocEntry, 0, len(sections))
+	for _, section := range sections {
+		entries = append(entries, tocEntry{title: sectionTitle(section), level: base})
+		if section != PDFSectionCourse {
+			continue
+		}
+
+		blocks := parseMarkdown(doc.Course)
+		top := 0
+		for _, block := range blocks {
+			if block.kind == mdHeading && (top == 0 || block.level < top) {
+				top = block.level
+			}
+		}
+
+		previous := base
+		for _, block := range blocks {
+			if block.kind != mdHeading {
+				continue
+			}
+			level := min(base+1+block.level-top, previous+1)
+			entries = append(entries, tocEntry{title: plainInline(block.text), level: level})
+			previous = level
+		}
+	}
+	return entries
+}
+
+func (d *pdfDoc) setOutline(entries []tocEntry, layout pdfLayout) {
+	for i := range entries {
+		entries[i].link = d.AddLink()
+		if i < len(layout.pages) {
+			entries[i].page = layout.pages[i]
+		}
+	}
+	d.toc = entries
+	d.placed = make([]int, 0, len(entries))
+}
+
+func (d *pdfDoc) showsTableOfContents() bool {
+	return len(d.toc) >= tocMinEntries
+}
+
+func (d *pdfDoc) markEntry() {
+	if len(d.placed) >= len(d.toc) {
+		return
+	}
+	entry := d.toc[len(d.placed)]
+	y := d.GetY()
+	d.SetLink(entry.link, y, -1)
+	d.Bookmark(outlineText(entry.title), entry.level, y)
+	d.placed = append(d.placed, d.PageNo())
+}
+
+func (d *pdfDoc) layout() pdfLayout {
+	return pdfLayout{totalPages: d.PageCount(), pages: d.placed}
+}
+
+func (d *pdfDoc) ensureSpace(height float64) {
+	_, _, _, bottom := d.GetMargins()
+	_, pageHeight := d.GetPageSize()
+	if d.GetY()+height > pageHeight-bottom {
+		d.AddPage()
+	}
+}
+
+func (s *PDFService) writeTableOfContents(pdf *pdfDoc) {
+	tpl := pdf.tpl
+	pdf.SetFont(bodyFont, "B", tpl.Fonts.Heading)
+	pdf.setTextColor(tpl.Colors.Heading)
+	pdf.Cell(0, tpl.Fonts.Heading*0.57, "Sommaire")
+	pdf.Ln(tpl.Fonts.Heading * 0.71)
+
+	left, _, right, _ := pdf.GetMargins()
+	width, _ := pdf.GetPageSize()
+	available := width - left - right
+
+	for _, entry := range pdf.toc {
+		if entry.level > tocMaxLevel {
+			continue
+		}
+
+		style := ""
+		if entry.level == 0 {
+			style = "B"
+			pdf.setTextColor(tpl.Colors.Heading)
+		} else {
+			pdf.setTextColor(tpl.Colors.Text)
+		}
+		pdf.SetFont(bodyFont, style, pdf.bodySize())
+
+		indent := float64(entry.level) * listIndent
+		titleWidth := available - indent - tocNumberWidth
+		page := ""
+		if entry.page > 0 {
+			page = strconv.Itoa(entry.page)
+		}
+
+		pdf.SetX(left + indent)
+		pdf.CellFormat(titleWidth, pdf.lineHeight()+1, fitText(pdf, entry.title, titleWidth-2), "", 0, "L", false, entry.link, "")
+		pdf.CellFormat(tocNumberWidth, pdf.lineHeight()+1, page, "", 1, "R", false, entry.link, "")
+	}
+	pdf.resetBody()
+}
+
+func fitText(pdf *pdfDoc, text string, width float64) string {
+	if pdf.GetStringWidth(text) <= width {
+		return text
+	}
+	runes := []rune(text)
+	for len(runes) > 0 && pdf.GetStringWidth(string(runes)+"…") > width {
+		runes = runes[:len(runes)-1]
+	}
+	return strings.TrimSpace(string(runes)) + "…"
+}
+
+func outlineText(text string) string {
+	units := utf16.Encode([]rune(text))
+	encoded := make([]byte, 2, 2+2*len(units))
+	encoded[0], encoded[1] = 0xfe, 0xff
+	for _, unit := range units {
+		encoded = append(encoded, byte(unit>>8), byte(unit))
+	}
+	return string(encoded)
+}
@@ -0,0 +1,88 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"myProfessor/internal/domain"
+)
+
+func TestDocumentOutlineLevels(t *testing.T) {
+	doc := domain.Document{Course: "Intro\n\n## Partie **A**\n\n#### Détail\n\n### Suite\n\n## Partie B", Summary: "résumé"}
+
+	entries := documentOutline(doc, includedSections(doc, nil), 0)
+	want := []tocEntry{
+		{title: "Cours", level: 0},
+		{title: "Partie A", level: 1},
+		{title: "Détail", level: 2},
+		{title: "Suite", level: 2},
+		{title: "Partie B", level: 1},
+		{title: "Résumé", level: 0},
+	}
+	if len(entries) != len(want) {
+		t.Fatalf("expected %d entries, got %+v", len(want), entries)
+	}
+	for i := range want {
+		if entries[i].title != want[i].title || entries[i].level != want[i].level {
+			t.Fatalf("entry %d: expected %+v, got %+v", i, want[i], entries[i])
+		}
+	}
+}
+
+func TestGeneratePDFBuildsTableOfContents(t *testing.T) {
+	var course strings.Builder
+	for chapter := 1; chapter <= 3; chapter++ {
+		fmt.Fprintf(&course, "# Chapitre %d\n\n", chapter)
+		for i := 0; i < 25; i++ {
+			course.WriteString("Une démonstration détaillée qui occupe plusieurs lignes afin de remplir la page courante du document généré.\n\n")
+		}
+	}
+	doc := domain.Document{ID: "doc-4", Title: "Analyse", Course: course.String(), Summary: "Points clés", Transcription: "Bonjour."}
+
+	path := filepath.Join(t.TempDir(), "toc.pdf")
+	if err := NewPDFService().GeneratePDF(doc, domain.Folder{}, path, PDFOptions{}); err != nil {
+		t.Fatalf("generate pdf: %v", err)
+	}
+
+	pages := extractPDFPages(t, path)
+	if len(pages) < 4 || !strings.Contains(pages[0], "Sommaire") {
+		t.Fatalf("expected a table of contents on the first page of a multi-page pdf, got %d pages", len(pages))
+	}
+
+	toc := strings.Split(pages[0], "\n")
+	for _, title := range []string{"Cours", "Chapitre 1", "Chapitre 2", "Chapitre 3", "Résumé", "Transcription"} {
+		page := tocPage(toc, title)
+		if page < 2 || page > len(pages) {
+			t.Fatalf("expected a page number for %q in the table of contents, got %d:\n%s", title, page, pages[0])
+		}
+		if !strings.Contains(pages[page-1], title) {
+			t.Errorf("table of contents points %q to page %d which does not contain it", title, page)
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read pdf: %v", err)
+	}
+	if !bytes.Contains(data, []byte("/Outlines")) || !bytes.Contains(data, []byte("/PageMode /UseOutlines")) {
+		t.Error("expected a pdf outline")
+	}
+	if bytes.Count(data, []byte("/Subtype /Link")) < 6 {
+		t.Error("expected clickable table of contents entries")
+	}
+}
+
+func tocPage(lines []string, title string) int {
+	for i, line := range lines {
+		if line == title && i+1 < len(lines) {
+			var page int
+			fmt.Sscanf(lines[i+1], "%d", &page)
+			return page
+		}
+	}
+	return 0
+}
@@ -0,0 +1,142 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"myProfessor/internal/domain"
+)
+
+const (
+	minQuizChoices = 2
+	maxQuizChoices = 6
+)
+
+const quizSystemPrompt = `Tu es un enseignant qui prépare un quiz de révision à partir d'une transcription de cours.
+Réponds uniquement avec un objet JSON de la forme :
+{"title": "...", "questions": [
+  {"type": "mcq", "question": "...", "choices": ["...", "..."], "answer": 0, "explanation": "..."},
+  {"type": "short", "question": "...", "answer": "...", "explanation": "..."}
+]}
+Pour "mcq", "answer" est l'indice (à partir de 0) de la bonne réponse parmi 2 à 6 choix.
+Pour "short", "answer" est la réponse attendue, courte. Chaque question a une explication.`
+
+const gradeSystemPrompt = `Tu corriges la réponse libre d'un étudiant à une question de cours.
+Compare-la à la réponse attendue en tolérant les reformulations et fautes d'orthographe.
+Réponds uniquement avec un objet JSON {"score": <nombre entre 0 et 1>, "feedback": "..."}.`
+
+func (s *OpenAIService) GenerateQuiz(ctx context.Context, transcription string, questionCount int, instructions string) (string, error) {
+	prompt := fmt.Sprintf("Nombre de questions : %d, en mélangeant QCM et réponses courtes.", questionCount)
+	if strings.TrimSpace(instructions) != "" {
+		prompt += "\n" + instructions
+	}
+	return s.completeJSON(ctx, promptMessages(quizSystemPrompt, transcription, prompt))
+}
+
+func (s *OpenAIService) GradeAnswer(ctx context.Context, question, expected, answer string) (float64, string, error) {
+	content := fmt.Sprintf("Question : %s\nRéponse attendue : %s\nRéponse de l'étudiant : %s", question, expected, answer)
+	raw, err := s.completeJSON(ctx, []chatMessage{
+		{Role: "system", Content: gradeSystemPrompt},
+		{Role: "user", Content: content},
+	})
+	if err != nil {
+		return 0, "", err
+	}
+
+	var grade struct {
+		Score    *float64 `json:"score"`
+		Feedback string   `json:"feedback"`
+	}
+	if err := json.Unmarshal([]byte(raw), &grade); err != nil {
+		return 0, "", fmt.Errorf("decode grade: %w", err)
+	}
+	if grade.Score == nil {
+		return 0, "", errors.New("grade has no score")
+	}
+	return min(max(*grade.Score, 0), 1), strings.TrimSpace(grade.Feedback), nil
+}
+
+func ParseQuiz(raw string, count int) (string, []domain.QuizQuestion, error) {
+	raw = strings.TrimSpace(raw)
+	raw = strings.TrimPrefix(raw, "```json")
+	raw = strings.TrimPrefix(raw, "```")
+	raw = strings.TrimSuffix(raw, "```")
+
+	var payload struct {
+		Title     string `json:"title"`
+		Questions []struct {
+			Type        string          `json:"type"`
+			Question    string          `json:"question"`
+			Choices     []string        `json:"choices"`
+			Answer      json.RawMessage `json:"answer"`
+			Explanation string          `json:"explanation"`
+		} `json:"questions"`
+	}
+	if err := json.Unmarshal([]byte(raw), &payload); err != nil {
+		return "", nil, fmt.Errorf("decode quiz: %w", err)
+	}
+
+	if len(payload.Questions) == 0 {
+		return "", nil, errors.New("quiz has no questions")
+	}
+	if count > 0 && len(payload.Questions) > count {
+		payload.Questions = payload.Questions[:count]
+	}
+
+	questions := make([]domain.QuizQuestion, 0, len(payload.Questions))
+	for i, item := range payload.Questions {
+		question := domain.QuizQuestion{
+			ID:          fmt.Sprintf("q%d", i+1),
+			Type:        strings.ToLower(strings.TrimSpace(item.Type)),
+			Prompt:      strings.TrimSpace(item.Question),
+			Explanation: strings.TrimSpace(item.Explanation),
+		}
+		if question.Prompt == "" {
+			return "", nil, fmt.Errorf("question %d has no text", i+1)
+		}
+		if question.Explanation == "" {
+			return "", nil, fmt.Errorf("question %d has no explanation", i+1)
+		}
+
+		switch question.Type {
+		case domain.QuizQuestionMultipleChoice:
+			if len(item.Choices) < minQuizChoices || len(item.Choices) > maxQuizChoices {
+				return "", nil, fmt.Errorf("question %d must have between %d and %d choices", i+1, minQuizChoices, maxQuizChoices)
+			}
+			seen := map[string]bool{}
+			for _, choice := range item.Choices {
+				choice = strings.TrimSpace(choice)
+				if choice == "" || seen[strings.ToLower(choice)] {
+					return "", nil, fmt.Errorf("question %d has empty or duplicate choices", i+1)
+				}
+				seen[strings.ToLower(choice)] = true
+				question.Choices = append(question.Choices, choice)
+			}
+
+			var index int
+			if err := json.Unmarshal(item.Answer, &index); err != nil {
+				return "", nil, fmt.Errorf("question %d answer must be a choice index", i+1)
+			}
+			if index < 0 || index >= len(question.Choices) {
+				return "", nil, fmt.Errorf("question %d answer index %d is out of range", i+1, index)
+			}
+			question.AnswerIndex = &index
+			question.Answer = question.Choices[index]
+		case domain.QuizQuestionShortAnswer:
+			var answer string
+			if err := json.Unmarshal(item.Answer, &answer); err != nil || strings.TrimSpace(answer) == "" {
+				return "", nil, fmt.Errorf("question %d answer must be a non-empty string", i+1)
+			}
+			question.Answer = strings.TrimSpace(answer)
+		default:
+			return "", nil, fmt.Errorf("question %d has unknown type %q", i+1, item.Type)
+		}
+
+		questions = append(questions, question)
+	}
+
+	return strings.TrimSpace(payload.Title), questions, nil
+}
@@ -0,0 +1,47 @@
+package services
+
+import "testing"
+
+func TestParseQuizValidatesQuestions(t *testing.T) {
+	raw := "```json\n" + `{"title":"Révisions","questions":[
+		{"type":"mcq","question":"2+2 ?","choices":["3","4"],"answer":1,"explanation":"Calcul."},
+		{"type":"short","question":"Capitale ?","answer":"Paris","explanation":"Géographie."}
+	]}` + "\n```"
+	title, questions, err := ParseQuiz(raw, 5)
+	if err != nil {
+		t.Fatalf("parse quiz: %v", err)
+	}
+	if title != "Révisions" || len(questions) != 2 {
+		t.Fatalf("unexpected quiz %q %+v", title, questions)
+	}
+	if questions[0].ID != "q1" || questions[0].AnswerIndex == nil || *questions[0].AnswerIndex != 1 || questions[0].Answer != "4" {
+		t.Fatalf("unexpected mcq question %+v", questions[0])
+	}
+
+	_, trimmed, err := ParseQuiz(`{"questions":[
+		{"type":"short","question":"Capitale ?","answer":"Paris","explanation":"Géographie."},
+		{"type":"short","question":"Fleuve ?","answer":"Seine","explanation":"Géographie."},
+		{"type":"essay","question":"Dissertez.","answer":"","explanation":"Hors sujet."}
+	]}`, 2)
+	if err != nil {
+		t.Fatalf("parse quiz with extra questions: %v", err)
+	}
+	if len(trimmed) != 2 || trimmed[1].Answer != "Seine" {
+		t.Fatalf("expected extra questions to be dropped, got %+v", trimmed)
+	}
+
+	invalid := map[string]string{
+		"no questions":    `{"questions":[]}`,
+		"index too large": `{"questions":[{"type":"mcq","question":"?","choices":["a","b"],"answer":2,"explanation":"x"}]}`,
+		"duplicate":       `{"questions":[{"type":"mcq","question":"?","choices":["a","A"],"answer":0,"explanation":"x"}]}`,
+		"empty answer":    `{"questions":[{"type":"short","question":"?","answer":"","explanation":"x"}]}`,
+		"no explanation":  `{"questions":[{"type":"short","question":"?","answer":"a"}]}`,
+		"unknown type":    `{"questions":[{"type":"essay","question":"?","answer":"a","explanation":"x"}]}`,
+		"not json":        `Voici votre quiz`,
+	}
+	for name, raw := range invalid {
+		if _, _, err := ParseQuiz(raw, 5); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
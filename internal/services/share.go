@@ -35,8 +35,15 @@ func NewShareService(cfg config.Config) *ShareService {
 }
 
 func (s *ShareService) Generate(docID string) (string, time.Time, error) {
+	return s.sign(fmt.Sprintf("/pdf/%s", docID))
+}
+
+func (s *ShareService) GenerateFolder(folderID string) (string, time.Time, error) {
+	return s.sign(fmt.Sprintf("/pdf/folders/%s", folderID))
+}
+
+func (s *ShareService) sign(path string) (string, time.Time, error) {
 	expiresAt := time.Now().Add(s.ttl)
-	path := fmt.Sprintf("/pdf/%s", docID)
 	signedPath := SignURL(path, expiresAt.Unix(), s.secret)
 
 	return s.baseURL + signedPath, expiresAt, nil
@@ -0,0 +1,130 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"unicode/utf8"
+
+	"myProfessor/internal/domain"
+)
+
+const (
+	SubtitleFormatSRT = "srt"
+	SubtitleFormatVTT = "vtt"
+
+	subtitleLineWidth    = 42
+	subtitleLinesPerCue  = 2
+	subtitleMinCueLength = 0.5
+)
+
+type subtitleCue struct {
+	start float64
+	end   float64
+	lines []string
+}
+
+func RenderSubtitles(segments []domain.Segment, format string) (string, error) {
+	cues := buildCues(segments)
+
+	var b strings.Builder
+	switch format {
+	case SubtitleFormatSRT:
+		for i, cue := range cues {
+			fmt.Fprintf(&b, "%d\n%s --> %s\n%s\n\n",
+				i+1,
+				formatCueTimestamp(cue.start, ","),
+				formatCueTimestamp(cue.end, ","),
+				strings.Join(cue.lines, "\n"))
+		}
+	case SubtitleFormatVTT:
+		b.WriteString("WEBVTT\n\n")
+		for i, cue := range cues {
+			fmt.Fprintf(&b, "%d\n%s --> %s\n%s\n\n",
+				i+1,
+				formatCueTimestamp(cue.start, "."),
+				formatCueTimestamp(cue.end, "."),
+				strings.Join(cue.lines, "\n"))
+		}
+	default:
+		return "", fmt.Errorf("unsupported subtitle format %q", format)
+	}
+
+	return b.String(), nil
+}
+
+func buildCues(segments []domain.Segment) []subtitleCue {
+	cues := make([]subtitleCue, 0, len(segments))
+
+	for _, segment := range segments {
+		lines := wrapSubtitleText(segment.Text, subtitleLineWidth)
+		if len(lines) == 0 {
+			continue
+		}
+
+		end := segment.End
+		if end-segment.Start < subtitleMinCueLength {
+			end = segment.Start + subtitleMinCueLength
+		}
+
+		total := 0
+		for _, line := range lines {
+			total += utf8.RuneCountInString(line)
+		}
+
+		start := segment.Start
+		consumed := 0
+		for i := 0; i < len(lines); i += subtitleLinesPerCue {
+			group := lines[i:min(i+subtitleLinesPerCue, len(lines))]
+			for _, line := range group {
+				consumed += utf8.RuneCountInString(line)
+			}
+
+			cueEnd := end
+			if i+subtitleLinesPerCue < len(lines) {
+				cueEnd = segment.Start + (end-segment.Start)*float64(consumed)/float64(total)
+			}
+			cues = append(cues, subtitleCue{start: start, end: cueEnd, lines: group})
+			start = cueEnd
+		}
+	}
+
+	return cues
+}
+
+func wrapSubtitleText(text string, width int) []string {
+	words := strings.Fields(text)
+	lines := make([]string, 0)
+
+	current := ""
+	for _, word := range words {
+		if current == "" {
+			current = word
+			continue
+		}
+		if utf8.RuneCountInString(current)+1+utf8.RuneCountInString(word) > width {
+			lines = append(lines, current)
+			current = word
+			continue
+		}
+		current += " " + word
+	}
+	if current != "" {
+		lines = append(lines, current)
+	}
+
+	return lines
+}
+
+func formatCueTimestamp(seconds float64, millisSeparator string) string {
+	if seconds < 0 {
+		seconds = 0
+	}
+	totalMillis := int64(math.Round(seconds * 1000))
+	hours := totalMillis / 3_600_000
+	minutes := totalMillis / 60_000 % 60
+	secs := totalMillis / 1000 % 60
+	millis := totalMillis % 1000
+
+	return fmt.Sprintf("%02d:%02d:%02d%s%03d", hours, minutes, secs, millisSeparator, millis)
+}
@@ -0,0 +1,126 @@
+package services
+
+import (
+	"strings"
+	"unicode"
+
+	"myProfessor/internal/domain"
+)
+
+const (
+	maxOverlapWords = 80
+	minOverlapWords = 3
+	edgeSlackWords  = 2
+)
+
+type Transcript struct {
+	Text     string
+	Segments []domain.Segment
+}
+
+func ShiftSegments(segments []domain.Segment, offset float64) []domain.Segment {
+	shifted := make([]domain.Segment, 0, len(segments))
+	for _, segment := range segments {
+		segment.Start += offset
+		segment.End += offset
+		if len(segment.Words) > 0 {
+			words := make([]domain.Word, len(segment.Words))
+			for i, word := range segment.Words {
+				word.Start += offset
+				word.End += offset
+				words[i] = word
+			}
+			segment.Words = words
+		}
+		shifted = append(shifted, segment)
+	}
+	return shifted
+}
+
+func MergeSegments(merged, next []domain.Segment) []domain.Segment {
+	if len(merged) == 0 {
+		return append(merged, next...)
+	}
+
+	lastEnd := merged[len(merged)-1].End
+	for _, segment := range next {
+		if (segment.Start+segment.End)/2 < lastEnd {
+			continue
+		}
+		merged = append(merged, segment)
+	}
+	return merged
+}
+
+func attachWords(segments []domain.Segment, words []domain.Word) {
+	idx := 0
+	for i := range segments {
+		for idx < len(words) && words[idx].Start < segments[i].End {
+			if words[idx].Start >= segments[i].Start {
+				segments[i].Words = append(segments[i].Words, words[idx])
+			}
+			idx++
+		}
+	}
+}
+
+func MergeTranscripts(parts []string) string {
+	merged := make([]string, 0)
+
+	for _, part := range parts {
+		words := strings.Fields(part)
+		if len(words) == 0 {
+			continue
+		}
+		if len(merged) == 0 {
+			merged = append(merged, words...)
+			continue
+		}
+
+		keep, skip := findOverlap(merged, words)
+		merged = append(merged[:keep], words[skip:]...)
+	}
+
+	return strings.Join(merged, " ")
+}
+
+func findOverlap(prev, next []string) (int, int) {
+	maxK := maxOverlapWords
+	if len(prev) < maxK {
+		maxK = len(prev)
+	}
+	if len(next) < maxK {
+		maxK = len(next)
+	}
+
+	for k := maxK; k >= minOverlapWords; k-- {
+		for j := 0; j <= edgeSlackWords && j+k <= len(prev); j++ {
+			tail := prev[len(prev)-j-k : len(prev)-j]
+			for i := 0; i <= edgeSlackWords && i+k <= len(next); i++ {
+				if equalWords(tail, next[i:i+k]) {
+					return len(prev) - j, i + k
+				}
+			}
+		}
+	}
+
+	return len(prev), 0
+}
+
+func equalWords(a, b []string) bool {
+	for i := range a {
+		if normalizeWord(a[i]) != normalizeWord(b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func normalizeWord(word string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, word)
+}
@@ -0,0 +1,53 @@
+package services
+
+import (
+	"testing"
+
+	"myProfessor/internal/domain"
+)
+
+func TestMergeTranscriptsRemovesOverlap(t *testing.T) {
+	parts := []string{
+		"Aujourd'hui nous parlons des valeurs propres. Une matrice carrée admet",
+		"matrice carrée admet des valeurs propres si son polynôme caractéristique",
+		"son polynôme caractéristique a des racines.",
+	}
+
+	got := MergeTranscripts(parts)
+	want := "Aujourd'hui nous parlons des valeurs propres. Une matrice carrée admet des valeurs propres si son polynôme caractéristique a des racines."
+	if got != want {
+		t.Fatalf("unexpected merge:\n got: %q\nwant: %q", got, want)
+	}
+}
+
+func TestMergeTranscriptsToleratesCutWords(t *testing.T) {
+	parts := []string{
+		"le théorème de Pythagore s'applique aux triangles rec",
+		"orème de Pythagore s'applique aux triangles rectangles uniquement, comme vu la semaine dernière",
+	}
+
+	got := MergeTranscripts(parts)
+	want := "le théorème de Pythagore s'applique aux triangles rectangles uniquement, comme vu la semaine dernière"
+	if got != want {
+		t.Fatalf("unexpected merge:\n got: %q\nwant: %q", got, want)
+	}
+}
+
+func TestMergeSegmentsDropsOverlap(t *testing.T) {
+	first := []domain.Segment{
+		{Start: 0, End: 4, Text: "un"},
+		{Start: 4, End: 10, Text: "deux"},
+	}
+	second := ShiftSegments([]domain.Segment{
+		{Start: 0, End: 3, Text: "deux"},
+		{Start: 3, End: 7, Text: "trois"},
+	}, 7)
+
+	merged := MergeSegments(first, second)
+	if len(merged) != 3 {
+		t.Fatalf("expected 3 segments, got %+v", merged)
+	}
+	if merged[2].Text != "trois" || merged[2].Start != 10 || merged[2].End != 14 {
+		t.Fatalf("unexpected last segment: %+v", merged[2])
+	}
+}
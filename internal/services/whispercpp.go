@@ -0,0 +1,215 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"myProfessor/internal/config"
+	"myProfessor/internal/domain"
+)
+
+type WhisperCppService struct {
+	binary   string
+	model    string
+	language string
+	threads  int
+}
+
+type whisperCppSegment struct {
+	From int64
+	To   int64
+	Text string
+}
+
+func NewWhisperCppService(cfg config.Config) (*WhisperCppService, error) {
+	binary, err := exec.LookPath(cfg.WhisperCppBinary)
+	if err != nil {
+		return nil, fmt.Errorf("whisper.cpp binary %s not found: %w", cfg.WhisperCppBinary, err)
+	}
+
+	if _, err := os.Stat(cfg.WhisperCppModel); err != nil {
+		return nil, fmt.Errorf("whisper.cpp model: %w", err)
+	}
+
+	return &WhisperCppService{
+		binary:   binary,
+		model:    cfg.WhisperCppModel,
+		language: cfg.WhisperCppLanguage,
+		threads:  cfg.WhisperCppThreads,
+	}, nil
+}
+
+func (s *WhisperCppService) TranscribeAudio(ctx context.Context, path string) (Transcript, error) {
+	outDir, err := os.MkdirTemp("", "whispercpp-*")
+	if err != nil {
+		return Transcript{}, fmt.Errorf("create whisper.cpp output dir: %w", err)
+	}
+	defer os.RemoveAll(outDir)
+
+	outBase := filepath.Join(outDir, "transcript")
+	args := []string{
+		"-m", s.model,
+		"-f", path,
+		"-l", s.language,
+		"-oj",
+		"-osrt",
+		"-of", outBase,
+		"-np",
+	}
+	if s.threads > 0 {
+		args = append(args, "-t", strconv.Itoa(s.threads))
+	}
+
+	cmd := exec.CommandContext(ctx, s.binary, args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return Transcript{}, fmt.Errorf("whisper.cpp failed: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	segments, err := readWhisperCppOutput(outBase)
+	if err != nil {
+		return Transcript{}, err
+	}
+
+	transcript := Transcript{Text: joinWhisperCppSegments(segments)}
+	for _, segment := range segments {
+		if segment.Text == "" {
+			continue
+		}
+		transcript.Segments = append(transcript.Segments, domain.Segment{
+			Start: float64(segment.From) / 1000,
+			End:   float64(segment.To) / 1000,
+			Text:  segment.Text,
+		})
+	}
+	return transcript, nil
+}
+
+func readWhisperCppOutput(outBase string) ([]whisperCppSegment, error) {
+	data, err := os.ReadFile(outBase + ".json")
+	if err == nil {
+		return parseWhisperCppJSON(data)
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("read whisper.cpp json output: %w", err)
+	}
+
+	data, err = os.ReadFile(outBase + ".srt")
+	if err != nil {
+		return nil, fmt.Errorf("read whisper.cpp srt output: %w", err)
+	}
+	return parseSRT(data)
+}
+
+func parseWhisperCppJSON(data []byte) ([]whisperCppSegment, error) {
+	var output struct {
+		Transcription []struct {
+			Offsets struct {
+				From int64 `json:"from"`
+				To   int64 `json:"to"`
+			} `json:"offsets"`
+			Text string `json:"text"`
+		} `json:"transcription"`
+	}
+	if err := json.Unmarshal(data, &output); err != nil {
+		return nil, fmt.Errorf("decode whisper.cpp json output: %w", err)
+	}
+
+	segments := make([]whisperCppSegment, 0, len(output.Transcription))
+	for _, item := range output.Transcription {
+		segments = append(segments, whisperCppSegment{
+			From: item.Offsets.From,
+			To:   item.Offsets.To,
+			Text: strings.TrimSpace(item.Text),
+		})
+	}
+	return segments, nil
+}
+
+func parseSRT(data []byte) ([]whisperCppSegment, error) {
+	segments := make([]whisperCppSegment, 0)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+
+	var current *whisperCppSegment
+	var text []string
+	flush := func() {
+		if current != nil {
+			current.Text = strings.TrimSpace(strings.Join(text, " "))
+			segments = append(segments, *current)
+		}
+		current = nil
+		text = nil
+	}
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(strings.TrimPrefix(scanner.Text(), "\ufeff"))
+		switch {
+		case line == "":
+			flush()
+		case current == nil && strings.Contains(line, "-->"):
+			parts := strings.SplitN(line, "-->", 2)
+			from, err := parseSRTTimestamp(parts[0])
+			if err != nil {
+				return nil, err
+			}
+			to, err := parseSRTTimestamp(parts[1])
+			if err != nil {
+				return nil, err
+			}
+			current = &whisperCppSegment{From: from, To: to}
+		case current == nil:
+			// cue number
+		default:
+			text = append(text, line)
+		}
+	}
+	flush()
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read srt: %w", err)
+	}
+	return segments, nil
+}
+
+func parseSRTTimestamp(value string) (int64, error) {
+	value = strings.ReplaceAll(strings.TrimSpace(value), ",", ".")
+	parts := strings.Split(value, ":")
+	if len(parts) != 3 {
+		return 0, fmt.Errorf("invalid srt timestamp %q", value)
+	}
+
+	hours, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid srt timestamp %q: %w", value, err)
+	}
+	minutes, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid srt timestamp %q: %w", value, err)
+	}
+	seconds, err := strconv.ParseFloat(parts[2], 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid srt timestamp %q: %w", value, err)
+	}
+
+	return (hours*3600+minutes*60)*1000 + int64(seconds*1000+0.5), nil
+}
+
+func joinWhisperCppSegments(segments []whisperCppSegment) string {
+	texts := make([]string, 0, len(segments))
+	for _, segment := range segments {
+		if segment.Text != "" {
+			texts = append(texts, segment.Text)
+		}
+	}
+	return strings.Join(texts, " ")
+}
@@ -0,0 +1,42 @@
+package services
+
+import "testing"
+
+func TestParseWhisperCppJSON(t *testing.T) {
+	data := []byte(`{
+  "transcription": [
+    {"timestamps": {"from": "00:00:00,000", "to": "00:00:02,500"}, "offsets": {"from": 0, "to": 2500}, "text": " Bonjour à tous."},
+    {"timestamps": {"from": "00:00:02,500", "to": "00:00:05,000"}, "offsets": {"from": 2500, "to": 5000}, "text": " Aujourd'hui, les intégrales."}
+  ]
+}`)
+
+	segments, err := parseWhisperCppJSON(data)
+	if err != nil {
+		t.Fatalf("parse json: %v", err)
+	}
+	if len(segments) != 2 || segments[1].From != 2500 || segments[1].To != 5000 {
+		t.Fatalf("unexpected segments: %+v", segments)
+	}
+
+	if got := joinWhisperCppSegments(segments); got != "Bonjour à tous. Aujourd'hui, les intégrales." {
+		t.Fatalf("unexpected text: %q", got)
+	}
+}
+
+func TestParseSRT(t *testing.T) {
+	data := []byte("1\n00:00:00,000 --> 00:00:02,500\nBonjour à tous.\n\n2\n00:01:02,250 --> 00:01:05,000\nAujourd'hui,\nles intégrales.\n")
+
+	segments, err := parseSRT(data)
+	if err != nil {
+		t.Fatalf("parse srt: %v", err)
+	}
+	if len(segments) != 2 {
+		t.Fatalf("expected 2 segments, got %d", len(segments))
+	}
+	if segments[1].From != 62250 || segments[1].To != 65000 {
+		t.Fatalf("unexpected timing: %+v", segments[1])
+	}
+	if segments[1].Text != "Aujourd'hui, les intégrales." {
+		t.Fatalf("unexpected text: %q", segments[1].Text)
+	}
+}
@@ -0,0 +1,112 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"myProfessor/internal/domain"
+)
+
+type ConversationStore struct {
+	mu  sync.Mutex
+	dir string
+}
+
+func NewConversationStore(baseDir string) (*ConversationStore, error) {
+	dir := filepath.Join(baseDir, "conversations")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create conversations directory: %w", err)
+	}
+	return &ConversationStore{dir: dir}, nil
+}
+
+func (cs *ConversationStore) Get(scope, targetID string) (domain.Conversation, error) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	return cs.readLocked(scope, targetID)
+}
+
+func (cs *ConversationStore) Append(scope, targetID string, messages ...domain.ChatMessage) (domain.Conversation, error) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	conversation, err := cs.readLocked(scope, targetID)
+	if err != nil {
+		return domain.Conversation{}, err
+	}
+
+	now := time.Now().Unix()
+	for _, message := range messages {
+		if message.CreatedAt == 0 {
+			message.CreatedAt = now
+		}
+		conversation.Messages = append(conversation.Messages, message)
+	}
+	conversation.UpdatedAt = now
+
+	if err := cs.writeLocked(conversation); err != nil {
+		return domain.Conversation{}, err
+	}
+	return conversation, nil
+}
+
+func (cs *ConversationStore) Delete(scope, targetID string) error {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	if err := os.Remove(cs.path(scope, targetID)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("delete conversation: %w", err)
+	}
+	return nil
+}
+
+func (cs *ConversationStore) readLocked(scope, targetID string) (domain.Conversation, error) {
+	conversation := domain.Conversation{Scope: scope, TargetID: targetID, Messages: []domain.ChatMessage{}}
+
+	data, err := os.ReadFile(cs.path(scope, targetID))
+	if errors.Is(err, os.ErrNotExist) {
+		return conversation, nil
+	}
+	if err != nil {
+		return domain.Conversation{}, fmt.Errorf("read conversation: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &conversation); err != nil {
+		return domain.Conversation{}, fmt.Errorf("decode conversation: %w", err)
+	}
+	return conversation, nil
+}
+
+func (cs *ConversationStore) writeLocked(conversation domain.Conversation) error {
+	tmp, err := os.CreateTemp(cs.dir, "conversation-*.tmp")
+	if err != nil {
+		return fmt.Errorf("create temp conversation: %w", err)
+	}
+
+	encoder := json.NewEncoder(tmp)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(conversation); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("encode conversation: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("close temp conversation: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), cs.path(conversation.Scope, conversation.TargetID)); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("replace conversation file: %w", err)
+	}
+	return nil
+}
+
+func (cs *ConversationStore) path(scope, targetID string) string {
+	return filepath.Join(cs.dir, scope+"_"+filepath.Base(targetID)+".json")
+}
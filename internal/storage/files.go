@@ -2,6 +2,7 @@ package storage
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"io"
 	"mime"
@@ -24,11 +25,16 @@ type FileManager struct {
 
 const (
 	ffmpegBinary     = "ffmpeg"
+	ffprobeBinary    = "ffprobe"
 	maxWhisperBytes  = 25 * 1024 * 1024
 	compressedSuffix = "_compressed"
 	compressedExt    = ".mp3"
+	pcmSuffix        = "_16k"
+	pcmExt           = ".wav"
 )
 
+var ErrExceedsWhisperLimit = errors.New("exceeds Whisper limit")
+
 var compressionProfiles = []struct {
 	bitrate    string
 	sampleRate string
@@ -132,6 +138,10 @@ func (fm *FileManager) PDFPath(id string) string {
 	return filepath.Join(fm.pdfDir, fmt.Sprintf("%s.pdf", id))
 }
 
+func (fm *FileManager) FolderPDFPath(folderID string) string {
+	return filepath.Join(fm.pdfDir, fmt.Sprintf("folder-%s.pdf", folderID))
+}
+
 func (fm *FileManager) writeWithLimit(path string, sample []byte, file multipart.File) error {
 	if fm.maxUploadBytes > 0 && int64(len(sample)) > fm.maxUploadBytes {
 		return fmt.Errorf("audio file exceeds maximum size")
@@ -233,6 +243,13 @@ func (fm *FileManager) CompressAudio(inputPath string) (string, error) {
 		return output, nil
 	}
 
+	if duration, err := probeDuration(inputPath); err == nil {
+		smallest := compressionProfiles[len(compressionProfiles)-1]
+		if estimateEncodedBytes(duration, smallest.bitrate) > maxWhisperBytes {
+			return "", fmt.Errorf("audio of %.0f minutes %w even at %s", duration/60, ErrExceedsWhisperLimit, smallest.bitrate)
+		}
+	}
+
 	var lastErr error
 	for idx, profile := range compressionProfiles {
 		if idx > 0 {
@@ -272,7 +289,38 @@ func (fm *FileManager) CompressAudio(inputPath string) (string, error) {
 	if lastErr != nil {
 		return "", lastErr
 	}
-	return "", fmt.Errorf("compressed audio still exceeds Whisper limit after applying fallback profiles")
+	return "", fmt.Errorf("compressed audio still %w after applying fallback profiles", ErrExceedsWhisperLimit)
+}
+
+func (fm *FileManager) ConvertToPCM(inputPath string) (string, error) {
+	if inputPath == "" {
+		return "", fmt.Errorf("no audio path provided for conversion")
+	}
+
+	if _, err := exec.LookPath(ffmpegBinary); err != nil {
+		return "", fmt.Errorf("ffmpeg not found in PATH: %w", err)
+	}
+
+	base := strings.TrimSuffix(filepath.Base(inputPath), filepath.Ext(inputPath))
+	output := filepath.Join(fm.audioDir, base+pcmSuffix+pcmExt)
+
+	cmd := exec.Command(ffmpegBinary,
+		"-y",
+		"-i", inputPath,
+		"-vn",
+		"-ac", "1",
+		"-ar", "16000",
+		"-c:a", "pcm_s16le",
+		output,
+	)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		_ = os.Remove(output)
+		return "", fmt.Errorf("convert audio to pcm: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	return output, nil
 }
 
 func (fm *FileManager) ensureWithinWhisperLimit(path string) error {
@@ -282,8 +330,9 @@ func (fm *FileManager) ensureWithinWhisperLimit(path string) error {
 	}
 
 	if info.Size() > maxWhisperBytes {
-		return fmt.Errorf("compressed audio size %.2f MB exceeds Whisper limit of %.2f MB",
+		return fmt.Errorf("compressed audio size %.2f MB %w of %.2f MB",
 			float64(info.Size())/1024.0/1024.0,
+			ErrExceedsWhisperLimit,
 			float64(maxWhisperBytes)/1024.0/1024.0)
 	}
 	return nil
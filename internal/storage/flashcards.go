@@ -0,0 +1,234 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"myProfessor/internal/domain"
+)
+
+var ErrFlashcardNotFound = errors.New("flashcard not found")
+
+type FlashcardStore struct {
+	mu         sync.RWMutex
+	dir        string
+	byDocument map[string][]domain.Flashcard
+	documentOf map[string]string
+}
+
+func NewFlashcardStore(baseDir string) (*FlashcardStore, error) {
+	dir := filepath.Join(baseDir, "flashcards")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create flashcards directory: %w", err)
+	}
+
+	fs := &FlashcardStore{dir: dir, byDocument: map[string][]domain.Flashcard{}, documentOf: map[string]string{}}
+	if err := fs.load(); err != nil {
+		return nil, err
+	}
+	return fs, nil
+}
+
+func (fs *FlashcardStore) AddCards(documentID string, cards []domain.Flashcard, replace bool) ([]domain.Flashcard, error) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	existing := fs.byDocument[documentID]
+	if replace {
+		existing = nil
+	}
+
+	now := time.Now().Unix()
+	added := make([]domain.Flashcard, 0, len(cards))
+	for _, card := range cards {
+		card.ID = uuid.NewString()
+		card.DocumentID = documentID
+		if card.CreatedAt == 0 {
+			card.CreatedAt = now
+		}
+		added = append(added, card)
+	}
+
+	updated := append(slices.Clone(existing), added...)
+	if err := fs.writeLocked(documentID, updated); err != nil {
+		return nil, err
+	}
+
+	for _, card := range fs.byDocument[documentID] {
+		delete(fs.documentOf, card.ID)
+	}
+	for _, card := range updated {
+		fs.documentOf[card.ID] = documentID
+	}
+	fs.byDocument[documentID] = updated
+	return added, nil
+}
+
+func (fs *FlashcardStore) ListByDocument(documentID string) []domain.Flashcard {
+	fs.mu.RLock()
+	defer fs.mu.RUnlock()
+
+	cards := slices.Clone(fs.byDocument[documentID])
+	if cards == nil {
+		cards = []domain.Flashcard{}
+	}
+	return cards
+}
+
+func (fs *FlashcardStore) GetCard(id string) (domain.Flashcard, error) {
+	fs.mu.RLock()
+	defer fs.mu.RUnlock()
+
+	card, _, ok := fs.findLocked(id)
+	if !ok {
+		return domain.Flashcard{}, fmt.Errorf("flashcard %s not found", id)
+	}
+	return card, nil
+}
+
+func (fs *FlashcardStore) Review(id string, fn func(domain.Flashcard) (domain.Flashcard, error)) (domain.Flashcard, error) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	current, index, ok := fs.findLocked(id)
+	if !ok {
+		return domain.Flashcard{}, fmt.Errorf("%w: %s", ErrFlashcardNotFound, id)
+	}
+
+	card, err := fn(current)
+	if err != nil {
+		return domain.Flashcard{}, err
+	}
+	card.ID = current.ID
+	card.DocumentID = current.DocumentID
+	card.CreatedAt = current.CreatedAt
+
+	updated := slices.Clone(fs.byDocument[current.DocumentID])
+	updated[index] = card
+	if err := fs.writeLocked(current.DocumentID, updated); err != nil {
+		return domain.Flashcard{}, err
+	}
+	fs.byDocument[current.DocumentID] = updated
+	return card, nil
+}
+
+func (fs *FlashcardStore) Due(now int64, allow func(documentID string) bool, limit int) []domain.Flashcard {
+	fs.mu.RLock()
+	defer fs.mu.RUnlock()
+
+	due := make([]domain.Flashcard, 0)
+	for documentID, cards := range fs.byDocument {
+		if allow != nil && !allow(documentID) {
+			continue
+		}
+		for _, card := range cards {
+			if card.DueAt <= now {
+				due = append(due, card)
+			}
+		}
+	}
+
+	sort.Slice(due, func(i, j int) bool {
+		if due[i].DueAt != due[j].DueAt {
+			return due[i].DueAt < due[j].DueAt
+		}
+		return due[i].ID < due[j].ID
+	})
+	if limit > 0 && len(due) > limit {
+		due = due[:limit]
+	}
+	return due
+}
+
+func (fs *FlashcardStore) DeleteByDocument(documentID string) error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	if err := os.Remove(fs.path(documentID)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("delete flashcards: %w", err)
+	}
+	for _, card := range fs.byDocument[documentID] {
+		delete(fs.documentOf, card.ID)
+	}
+	delete(fs.byDocument, documentID)
+	return nil
+}
+
+func (fs *FlashcardStore) findLocked(id string) (domain.Flashcard, int, bool) {
+	documentID, ok := fs.documentOf[id]
+	if !ok {
+		return domain.Flashcard{}, 0, false
+	}
+	for i, card := range fs.byDocument[documentID] {
+		if card.ID == id {
+			return card, i, true
+		}
+	}
+	return domain.Flashcard{}, 0, false
+}
+
+func (fs *FlashcardStore) load() error {
+	paths, err := filepath.Glob(filepath.Join(fs.dir, "*.json"))
+	if err != nil {
+		return fmt.Errorf("list flashcards: %w", err)
+	}
+
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("read flashcards %s: %w", path, err)
+		}
+
+		var cards []domain.Flashcard
+		if err := json.Unmarshal(data, &cards); err != nil {
+			log.Printf("ignoring unreadable flashcards file %s: %v", path, err)
+			continue
+		}
+		for _, card := range cards {
+			if card.ID == "" || card.DocumentID == "" {
+				continue
+			}
+			fs.byDocument[card.DocumentID] = append(fs.byDocument[card.DocumentID], card)
+			fs.documentOf[card.ID] = card.DocumentID
+		}
+	}
+	return nil
+}
+
+func (fs *FlashcardStore) writeLocked(documentID string, cards []domain.Flashcard) error {
+	tmp, err := os.CreateTemp(fs.dir, "flashcards-*.tmp")
+	if err != nil {
+		return fmt.Errorf("create temp flashcards: %w", err)
+	}
+
+	encoder := json.NewEncoder(tmp)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(cards); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("encode flashcards: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("close temp flashcards: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), fs.path(documentID)); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("replace flashcards file: %w", err)
+	}
+	return nil
+}
+
+func (fs *FlashcardStore) path(documentID string) string {
+	return filepath.Join(fs.dir, filepath.Base(documentID)+".json")
+}
@@ -0,0 +1,63 @@
+package storage
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"myProfessor/internal/domain"
+)
+
+func TestFlashcardReviewIsAtomic(t *testing.T) {
+	dir := t.TempDir()
+	fs, err := NewFlashcardStore(dir)
+	if err != nil {
+		t.Fatalf("new flashcard store: %v", err)
+	}
+	added, err := fs.AddCards("doc", []domain.Flashcard{{Front: "Recto", Back: "Verso"}}, false)
+	if err != nil {
+		t.Fatalf("add cards: %v", err)
+	}
+	id := added[0].ID
+
+	const reviews = 20
+	var wg sync.WaitGroup
+	for i := 0; i < reviews; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := fs.Review(id, func(card domain.Flashcard) (domain.Flashcard, error) {
+				card.Repetitions++
+				return card, nil
+			})
+			if err != nil {
+				t.Errorf("review: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	card, err := fs.GetCard(id)
+	if err != nil {
+		t.Fatalf("get card: %v", err)
+	}
+	if card.Repetitions != reviews {
+		t.Fatalf("expected %d repetitions, got %d", reviews, card.Repetitions)
+	}
+
+	reloaded, err := NewFlashcardStore(dir)
+	if err != nil {
+		t.Fatalf("reload flashcard store: %v", err)
+	}
+	if card, _ := reloaded.GetCard(id); card.Repetitions != reviews {
+		t.Fatalf("expected %d persisted repetitions, got %d", reviews, card.Repetitions)
+	}
+
+	failure := errors.New("rejected")
+	if _, err := fs.Review(id, func(card domain.Flashcard) (domain.Flashcard, error) { return card, failure }); !errors.Is(err, failure) {
+		t.Fatalf("expected callback error, got %v", err)
+	}
+	if _, err := fs.Review("missing", func(card domain.Flashcard) (domain.Flashcard, error) { return card, nil }); !errors.Is(err, ErrFlashcardNotFound) {
+		t.Fatalf("expected ErrFlashcardNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,414 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"myProfessor/internal/domain"
+)
+
+type metaData struct {
+	SchemaVersion int                        `json:"schemaVersion"`
+	Folders       map[string]domain.Folder   `json:"folders"`
+	Documents     map[string]domain.Document `json:"documents"`
+}
+
+type JSONStore struct {
+	mu      sync.RWMutex
+	path    string
+	data    metaData
+	changes changeNotifier
+}
+
+func NewJSONStore(baseDir string) (*JSONStore, error) {
+	if err := os.MkdirAll(baseDir, 0o755); err != nil {
+		return nil, fmt.Errorf("create data directory: %w", err)
+	}
+
+	store := &JSONStore{path: filepath.Join(baseDir, "meta.json")}
+	if err := store.Load(); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+func (s *JSONStore) Load() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.data = metaData{
+		SchemaVersion: currentSchemaVersion(),
+		Folders:       map[string]domain.Folder{},
+		Documents:     map[string]domain.Document{},
+	}
+
+	raw, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s.saveLocked()
+	}
+	if err != nil {
+		return fmt.Errorf("read meta file: %w", err)
+	}
+	if len(bytes.TrimSpace(raw)) == 0 {
+		return s.saveLocked()
+	}
+
+	s.data.SchemaVersion = 0
+	if err := json.Unmarshal(raw, &s.data); err != nil {
+		return fmt.Errorf("decode meta file: %w", err)
+	}
+	s.ensureMaps()
+
+	from := s.data.SchemaVersion
+	applied, err := migrateMeta(&s.data)
+	if err != nil {
+		return err
+	}
+	if len(applied) == 0 {
+		return nil
+	}
+
+	backup, err := backupMetaFile(s.path, raw, from)
+	if err != nil {
+		return err
+	}
+	log.Printf("meta file upgraded from schema version %d to %d (%s), previous file saved as %s",
+		from, s.data.SchemaVersion, strings.Join(applied, ", "), backup)
+	return s.saveLocked()
+}
+
+func (s *JSONStore) Save() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.saveLocked()
+}
+
+func (s *JSONStore) Close() error {
+	return nil
+}
+
+func (s *JSONStore) OnDocumentChange(fn func(doc domain.Document, deleted bool)) {
+	s.changes.set(fn)
+}
+
+func (s *JSONStore) CreateFolder(name string) (domain.Folder, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.ensureMaps()
+	id := uuid.NewString()
+	now := time.Now().Unix()
+	folder := domain.Folder{
+		ID:          id,
+		Name:        name,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+		DocumentIDs: []string{},
+	}
+
+	s.data.Folders[id] = folder
+
+	if err := s.saveLocked(); err != nil {
+		return domain.Folder{}, err
+	}
+
+	return folder, nil
+}
+
+func (s *JSONStore) ListFolders() []domain.Folder {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	folders := make([]domain.Folder, 0, len(s.data.Folders))
+	for _, folder := range s.data.Folders {
+		folders = append(folders, folder)
+	}
+	return folders
+}
+
+func (s *JSONStore) GetFolder(id string) (domain.Folder, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	folder, ok := s.data.Folders[id]
+	if !ok {
+		return domain.Folder{}, fmt.Errorf("folder %s not found", id)
+	}
+	return folder, nil
+}
+
+func (s *JSONStore) RenameFolder(id, newName string) (domain.Folder, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	folder, ok := s.data.Folders[id]
+	if !ok {
+		return domain.Folder{}, fmt.Errorf("folder %s not found", id)
+	}
+
+	folder.Name = newName
+	folder.UpdatedAt = time.Now().Unix()
+	s.data.Folders[id] = folder
+
+	if err := s.saveLocked(); err != nil {
+		return domain.Folder{}, err
+	}
+
+	return folder, nil
+}
+
+func (s *JSONStore) SetFolderPDFTemplate(id, template string) (domain.Folder, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	folder, ok := s.data.Folders[id]
+	if !ok {
+		return domain.Folder{}, fmt.Errorf("folder %s not found", id)
+	}
+
+	folder.PDFTemplate = template
+	folder.UpdatedAt = time.Now().Unix()
+	s.data.Folders[id] = folder
+
+	if err := s.saveLocked(); err != nil {
+		return domain.Folder{}, err
+	}
+
+	return folder, nil
+}
+
+func (s *JSONStore) DeleteFolder(id string) error {
+	defer s.changes.flush()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	folder, ok := s.data.Folders[id]
+	if !ok {
+		return fmt.Errorf("folder %s not found", id)
+	}
+
+	deleted := make([]domain.Document, 0, len(folder.DocumentIDs))
+	for _, docID := range folder.DocumentIDs {
+		if doc, ok := s.data.Documents[docID]; ok {
+			deleted = append(deleted, doc)
+		}
+		delete(s.data.Documents, docID)
+	}
+
+	delete(s.data.Folders, id)
+
+	if err := s.saveLocked(); err != nil {
+		return err
+	}
+	for _, doc := range deleted {
+		s.changes.enqueue(doc, true)
+	}
+	return nil
+}
+
+func (s *JSONStore) CreateDocument(doc domain.Document) (domain.Document, error) {
+	defer s.changes.flush()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.ensureMaps()
+
+	if doc.ID == "" {
+		doc.ID = uuid.NewString()
+	}
+	if doc.ProcessingStatus == "" {
+		doc.ProcessingStatus = domain.ProcessingStatusPending
+	}
+	now := time.Now().Unix()
+	if doc.CreatedAt == 0 {
+		doc.CreatedAt = now
+	}
+	doc.UpdatedAt = now
+
+	s.data.Documents[doc.ID] = doc.Clone()
+	s.attachDocumentToFolder(doc.FolderID, doc.ID)
+
+	if err := s.saveLocked(); err != nil {
+		return domain.Document{}, err
+	}
+	s.changes.enqueue(doc, false)
+
+	return doc, nil
+}
+
+func (s *JSONStore) ListDocumentsByFolder(folderID string) []domain.Document {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	docs := make([]domain.Document, 0)
+	for _, doc := range s.data.Documents {
+		if doc.FolderID == folderID {
+			docs = append(docs, doc.Clone())
+		}
+	}
+	return docs
+}
+
+func (s *JSONStore) ListDocuments() []domain.Document {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	docs := make([]domain.Document, 0, len(s.data.Documents))
+	for _, doc := range s.data.Documents {
+		docs = append(docs, doc.Clone())
+	}
+	return docs
+}
+
+func (s *JSONStore) GetDocument(id string) (domain.Document, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	doc, ok := s.data.Documents[id]
+	if !ok {
+		return domain.Document{}, fmt.Errorf("document %s not found", id)
+	}
+	return doc.Clone(), nil
+}
+
+func (s *JSONStore) UpdateDocument(doc domain.Document) (domain.Document, error) {
+	defer s.changes.flush()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, ok := s.data.Documents[doc.ID]
+	if !ok {
+		return domain.Document{}, fmt.Errorf("document %s not found", doc.ID)
+	}
+
+	if doc.CreatedAt == 0 {
+		doc.CreatedAt = existing.CreatedAt
+	}
+
+	if doc.FolderID != existing.FolderID {
+		s.detachDocumentFromFolder(existing.FolderID, doc.ID)
+		s.attachDocumentToFolder(doc.FolderID, doc.ID)
+	}
+
+	if doc.ProcessingStatus == "" {
+		doc.ProcessingStatus = existing.ProcessingStatus
+	}
+
+	doc.UpdatedAt = time.Now().Unix()
+	s.data.Documents[doc.ID] = doc.Clone()
+
+	if err := s.saveLocked(); err != nil {
+		return domain.Document{}, err
+	}
+	s.changes.enqueue(doc, false)
+	return doc, nil
+}
+
+func (s *JSONStore) DeleteDocument(id string) error {
+	defer s.changes.flush()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	doc, ok := s.data.Documents[id]
+	if !ok {
+		return fmt.Errorf("document %s not found", id)
+	}
+
+	s.detachDocumentFromFolder(doc.FolderID, id)
+
+	delete(s.data.Documents, id)
+
+	if err := s.saveLocked(); err != nil {
+		return err
+	}
+	s.changes.enqueue(doc, true)
+	return nil
+}
+
+func (s *JSONStore) saveLocked() error {
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), "meta-*.json")
+	if err != nil {
+		return fmt.Errorf("create temp meta: %w", err)
+	}
+
+	encoder := json.NewEncoder(tmp)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(s.data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("encode meta: %w", err)
+	}
+
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("close temp meta: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("replace meta file: %w", err)
+	}
+
+	return nil
+}
+
+func (s *JSONStore) ensureMaps() {
+	if s.data.Folders == nil {
+		s.data.Folders = map[string]domain.Folder{}
+	}
+	if s.data.Documents == nil {
+		s.data.Documents = map[string]domain.Document{}
+	}
+}
+
+func (s *JSONStore) attachDocumentToFolder(folderID, docID string) {
+	if folderID == "" {
+		return
+	}
+
+	folder, ok := s.data.Folders[folderID]
+	if !ok {
+		return
+	}
+
+	for _, existing := range folder.DocumentIDs {
+		if existing == docID {
+			return
+		}
+	}
+
+	folder.DocumentIDs = append(folder.DocumentIDs, docID)
+	folder.UpdatedAt = time.Now().Unix()
+	s.data.Folders[folderID] = folder
+}
+
+func (s *JSONStore) detachDocumentFromFolder(folderID, docID string) {
+	if folderID == "" {
+		return
+	}
+
+	folder, ok := s.data.Folders[folderID]
+	if !ok {
+		return
+	}
+
+	updated := folder.DocumentIDs[:0]
+	for _, existing := range folder.DocumentIDs {
+		if existing != docID {
+			updated = append(updated, existing)
+		}
+	}
+	folder.DocumentIDs = updated
+	folder.UpdatedAt = time.Now().Unix()
+	s.data.Folders[folderID] = folder
+}
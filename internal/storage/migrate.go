@@ -0,0 +1,150 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+
+	"myProfessor/internal/domain"
+)
+
+const (
+	IssueMissingDocument  = "missing_document"
+	IssueDuplicateListing = "duplicate_listing"
+	IssueFolderMismatch   = "folder_mismatch"
+	IssueOrphanDocument   = "orphan_document"
+	IssueUnlistedDocument = "unlisted_document"
+)
+
+type Snapshot struct {
+	Folders   []domain.Folder
+	Documents []domain.Document
+}
+
+type MigrationIssue struct {
+	Kind       string
+	FolderID   string
+	DocumentID string
+	Detail     string
+}
+
+func (i MigrationIssue) String() string {
+	return fmt.Sprintf("%s: folder=%q document=%q %s", i.Kind, i.FolderID, i.DocumentID, i.Detail)
+}
+
+func ReadMetaFile(path string) (Snapshot, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return Snapshot{}, fmt.Errorf("read meta file: %w", err)
+	}
+
+	var data metaData
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return Snapshot{}, fmt.Errorf("decode meta file: %w", err)
+	}
+	if _, err := migrateMeta(&data); err != nil {
+		return Snapshot{}, err
+	}
+
+	snapshot := Snapshot{
+		Folders:   make([]domain.Folder, 0, len(data.Folders)),
+		Documents: make([]domain.Document, 0, len(data.Documents)),
+	}
+	for key, folder := range data.Folders {
+		if folder.ID == "" {
+			folder.ID = key
+		}
+		snapshot.Folders = append(snapshot.Folders, folder)
+	}
+	for key, doc := range data.Documents {
+		if doc.ID == "" {
+			doc.ID = key
+		}
+		snapshot.Documents = append(snapshot.Documents, doc)
+	}
+
+	sort.Slice(snapshot.Folders, func(i, j int) bool {
+		return snapshot.Folders[i].CreatedAt < snapshot.Folders[j].CreatedAt ||
+			snapshot.Folders[i].CreatedAt == snapshot.Folders[j].CreatedAt && snapshot.Folders[i].ID < snapshot.Folders[j].ID
+	})
+	sort.Slice(snapshot.Documents, func(i, j int) bool {
+		return snapshot.Documents[i].CreatedAt < snapshot.Documents[j].CreatedAt ||
+			snapshot.Documents[i].CreatedAt == snapshot.Documents[j].CreatedAt && snapshot.Documents[i].ID < snapshot.Documents[j].ID
+	})
+
+	return snapshot, nil
+}
+
+func ValidateSnapshot(snapshot Snapshot) []MigrationIssue {
+	issues := make([]MigrationIssue, 0)
+
+	folders := make(map[string]domain.Folder, len(snapshot.Folders))
+	for _, folder := range snapshot.Folders {
+		folders[folder.ID] = folder
+	}
+	docs := make(map[string]domain.Document, len(snapshot.Documents))
+	for _, doc := range snapshot.Documents {
+		docs[doc.ID] = doc
+	}
+
+	listedIn := map[string][]string{}
+	for _, folder := range snapshot.Folders {
+		for _, docID := range folder.DocumentIDs {
+			listedIn[docID] = append(listedIn[docID], folder.ID)
+
+			doc, ok := docs[docID]
+			if !ok {
+				issues = append(issues, MigrationIssue{
+					Kind:       IssueMissingDocument,
+					FolderID:   folder.ID,
+					DocumentID: docID,
+					Detail:     "folder lists a document that does not exist",
+				})
+				continue
+			}
+			if doc.FolderID != folder.ID {
+				issues = append(issues, MigrationIssue{
+					Kind:       IssueFolderMismatch,
+					FolderID:   folder.ID,
+					DocumentID: docID,
+					Detail:     fmt.Sprintf("document belongs to folder %q", doc.FolderID),
+				})
+			}
+		}
+	}
+
+	for _, doc := range snapshot.Documents {
+		if listed := listedIn[doc.ID]; len(listed) > 1 {
+			issues = append(issues, MigrationIssue{
+				Kind:       IssueDuplicateListing,
+				DocumentID: doc.ID,
+				Detail:     fmt.Sprintf("document is listed by %d folders %v", len(listed), listed),
+			})
+		}
+
+		if doc.FolderID == "" {
+			continue
+		}
+		if _, ok := folders[doc.FolderID]; !ok {
+			issues = append(issues, MigrationIssue{
+				Kind:       IssueOrphanDocument,
+				FolderID:   doc.FolderID,
+				DocumentID: doc.ID,
+				Detail:     "document references a folder that does not exist",
+			})
+			continue
+		}
+		if !slices.Contains(listedIn[doc.ID], doc.FolderID) {
+			issues = append(issues, MigrationIssue{
+				Kind:       IssueUnlistedDocument,
+				FolderID:   doc.FolderID,
+				DocumentID: doc.ID,
+				Detail:     "document is missing from its folder's document list",
+			})
+		}
+	}
+
+	return issues
+}
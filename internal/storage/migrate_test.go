@@ -0,0 +1,80 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"myProfessor/internal/domain"
+)
+
+const inconsistentMeta = `{
+  "folders": {
+    "f1": {"id": "f1", "name": "Maths", "documentIds": ["d1", "d2", "ghost"], "createdAt": 1, "updatedAt": 1},
+    "f2": {"id": "f2", "name": "Physique", "documentIds": ["d2"], "createdAt": 2, "updatedAt": 2}
+  },
+  "documents": {
+    "d1": {"id": "d1", "folderId": "f1", "title": "Intégrales", "transcription": "texte", "createdAt": 1, "updatedAt": 1},
+    "d2": {"id": "d2", "folderId": "f2", "title": "Forces", "createdAt": 2, "updatedAt": 2},
+    "d3": {"id": "d3", "folderId": "f2", "title": "Énergie", "createdAt": 3, "updatedAt": 3},
+    "d4": {"id": "d4", "folderId": "gone", "title": "Orphelin", "createdAt": 4, "updatedAt": 4}
+  }
+}`
+
+func TestMigrateMetaFileToSQLite(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "meta.json")
+	if err := os.WriteFile(source, []byte(inconsistentMeta), 0o644); err != nil {
+		t.Fatalf("write meta: %v", err)
+	}
+
+	snapshot, err := ReadMetaFile(source)
+	if err != nil {
+		t.Fatalf("read meta: %v", err)
+	}
+	if snapshot.Documents[0].ProcessingStatus != domain.ProcessingStatusCompleted {
+		t.Fatalf("expected processing status backfill, got %q", snapshot.Documents[0].ProcessingStatus)
+	}
+
+	counts := map[string]int{}
+	for _, issue := range ValidateSnapshot(snapshot) {
+		counts[issue.Kind]++
+	}
+	expected := map[string]int{
+		IssueMissingDocument:  1,
+		IssueFolderMismatch:   1,
+		IssueDuplicateListing: 1,
+		IssueUnlistedDocument: 1,
+		IssueOrphanDocument:   1,
+	}
+	for kind, count := range expected {
+		if counts[kind] != count {
+			t.Fatalf("expected %d %s issues, got %d (%v)", count, kind, counts[kind], counts)
+		}
+	}
+
+	store, err := NewSQLiteStore(filepath.Join(dir, "meta.db"))
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	defer store.Close()
+
+	if err := store.Import(snapshot); err != nil {
+		t.Fatalf("import: %v", err)
+	}
+
+	physics, err := store.GetFolder("f2")
+	if err != nil {
+		t.Fatalf("get folder: %v", err)
+	}
+	if len(physics.DocumentIDs) != 2 || physics.DocumentIDs[0] != "d2" || physics.DocumentIDs[1] != "d3" {
+		t.Fatalf("unexpected rebuilt membership %v", physics.DocumentIDs)
+	}
+	if len(store.ListDocuments()) != 4 {
+		t.Fatalf("expected all documents to be imported")
+	}
+
+	if err := store.Import(snapshot); err == nil {
+		t.Fatalf("expected import into a non-empty database to fail")
+	}
+}
@@ -0,0 +1,47 @@
+package storage
+
+import (
+	"sync"
+
+	"myProfessor/internal/domain"
+)
+
+type documentChange struct {
+	doc     domain.Document
+	deleted bool
+}
+
+type changeNotifier struct {
+	deliver  sync.Mutex
+	mu       sync.Mutex
+	queue    []documentChange
+	onChange func(doc domain.Document, deleted bool)
+}
+
+func (n *changeNotifier) set(fn func(doc domain.Document, deleted bool)) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.onChange = fn
+}
+
+func (n *changeNotifier) enqueue(doc domain.Document, deleted bool) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.onChange != nil {
+		n.queue = append(n.queue, documentChange{doc: doc.Clone(), deleted: deleted})
+	}
+}
+
+func (n *changeNotifier) flush() {
+	n.deliver.Lock()
+	defer n.deliver.Unlock()
+
+	n.mu.Lock()
+	queue, onChange := n.queue, n.onChange
+	n.queue = nil
+	n.mu.Unlock()
+
+	for _, change := range queue {
+		onChange(change.doc, change.deleted)
+	}
+}
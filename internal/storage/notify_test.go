@@ -0,0 +1,73 @@
+package storage
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"myProfessor/internal/domain"
+)
+
+func TestJSONStoreNotifiesAfterReleasingLock(t *testing.T) {
+	store, err := NewJSONStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	defer store.Close()
+
+	testNotifiesAfterReleasingLock(t, store)
+}
+
+func TestSQLiteStoreNotifiesAfterReleasingLock(t *testing.T) {
+	store, err := NewSQLiteStore(filepath.Join(t.TempDir(), "meta.db"))
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	defer store.Close()
+
+	testNotifiesAfterReleasingLock(t, store)
+}
+
+func testNotifiesAfterReleasingLock(t *testing.T, store Store) {
+	t.Helper()
+
+	var seen []string
+	store.OnDocumentChange(func(doc domain.Document, deleted bool) {
+		if deleted {
+			seen = append(seen, "deleted")
+			return
+		}
+		current, err := store.GetDocument(doc.ID)
+		if err != nil {
+			t.Errorf("get document from callback: %v", err)
+			return
+		}
+		seen = append(seen, current.Title)
+	})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		doc, err := store.CreateDocument(domain.Document{Title: "Brouillon"})
+		if err != nil {
+			t.Errorf("create document: %v", err)
+			return
+		}
+		doc.Title = "Cours"
+		if _, err := store.UpdateDocument(doc); err != nil {
+			t.Errorf("update document: %v", err)
+		}
+		if err := store.DeleteDocument(doc.ID); err != nil {
+			t.Errorf("delete document: %v", err)
+		}
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("store calls blocked: the change callback must run without the store lock")
+	}
+	if len(seen) != 3 || seen[0] != "Brouillon" || seen[1] != "Cours" || seen[2] != "deleted" {
+		t.Fatalf("unexpected notifications %v", seen)
+	}
+}
@@ -0,0 +1,197 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"myProfessor/internal/domain"
+)
+
+type quizRecord struct {
+	Quiz     domain.Quiz          `json:"quiz"`
+	Attempts []domain.QuizAttempt `json:"attempts"`
+}
+
+type QuizStore struct {
+	mu      sync.RWMutex
+	dir     string
+	records map[string]*quizRecord
+}
+
+func NewQuizStore(baseDir string) (*QuizStore, error) {
+	dir := filepath.Join(baseDir, "quizzes")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create quizzes directory: %w", err)
+	}
+
+	qs := &QuizStore{dir: dir, records: map[string]*quizRecord{}}
+	if err := qs.load(); err != nil {
+		return nil, err
+	}
+	return qs, nil
+}
+
+func (qs *QuizStore) CreateQuiz(quiz domain.Quiz) (domain.Quiz, error) {
+	qs.mu.Lock()
+	defer qs.mu.Unlock()
+
+	quiz.ID = uuid.NewString()
+	if quiz.CreatedAt == 0 {
+		quiz.CreatedAt = time.Now().Unix()
+	}
+	record := &quizRecord{Quiz: quiz, Attempts: []domain.QuizAttempt{}}
+	if err := qs.writeLocked(record); err != nil {
+		return domain.Quiz{}, err
+	}
+	qs.records[quiz.ID] = record
+	return cloneQuiz(quiz), nil
+}
+
+func (qs *QuizStore) GetQuiz(id string) (domain.Quiz, error) {
+	qs.mu.RLock()
+	defer qs.mu.RUnlock()
+
+	record, ok := qs.records[id]
+	if !ok {
+		return domain.Quiz{}, fmt.Errorf("quiz %s not found", id)
+	}
+	return cloneQuiz(record.Quiz), nil
+}
+
+func (qs *QuizStore) ListQuizzesByDocument(documentID string) []domain.Quiz {
+	qs.mu.RLock()
+	defer qs.mu.RUnlock()
+
+	quizzes := make([]domain.Quiz, 0)
+	for _, record := range qs.records {
+		if record.Quiz.DocumentID == documentID {
+			quizzes = append(quizzes, cloneQuiz(record.Quiz))
+		}
+	}
+	sort.Slice(quizzes, func(i, j int) bool {
+		return quizzes[i].CreatedAt > quizzes[j].CreatedAt
+	})
+	return quizzes
+}
+
+func (qs *QuizStore) AddAttempt(attempt domain.QuizAttempt) (domain.QuizAttempt, error) {
+	qs.mu.Lock()
+	defer qs.mu.Unlock()
+
+	record, ok := qs.records[attempt.QuizID]
+	if !ok {
+		return domain.QuizAttempt{}, fmt.Errorf("quiz %s not found", attempt.QuizID)
+	}
+
+	attempt.ID = uuid.NewString()
+	attempt.DocumentID = record.Quiz.DocumentID
+	if attempt.CreatedAt == 0 {
+		attempt.CreatedAt = time.Now().Unix()
+	}
+
+	updated := &quizRecord{Quiz: record.Quiz, Attempts: append(slices.Clone(record.Attempts), attempt)}
+	if err := qs.writeLocked(updated); err != nil {
+		return domain.QuizAttempt{}, err
+	}
+	qs.records[attempt.QuizID] = updated
+	return attempt, nil
+}
+
+func (qs *QuizStore) ListAttempts(quizID string) ([]domain.QuizAttempt, error) {
+	qs.mu.RLock()
+	defer qs.mu.RUnlock()
+
+	record, ok := qs.records[quizID]
+	if !ok {
+		return nil, fmt.Errorf("quiz %s not found", quizID)
+	}
+	return slices.Clone(record.Attempts), nil
+}
+
+func (qs *QuizStore) DeleteByDocument(documentID string) error {
+	qs.mu.Lock()
+	defer qs.mu.Unlock()
+
+	for id, record := range qs.records {
+		if record.Quiz.DocumentID != documentID {
+			continue
+		}
+		if err := os.Remove(qs.path(id)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("delete quiz %s: %w", id, err)
+		}
+		delete(qs.records, id)
+	}
+	return nil
+}
+
+func (qs *QuizStore) load() error {
+	paths, err := filepath.Glob(filepath.Join(qs.dir, "*.json"))
+	if err != nil {
+		return fmt.Errorf("list quizzes: %w", err)
+	}
+
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("read quiz %s: %w", path, err)
+		}
+
+		var record quizRecord
+		if err := json.Unmarshal(data, &record); err != nil {
+			log.Printf("ignoring unreadable quiz file %s: %v", path, err)
+			continue
+		}
+		if record.Quiz.ID == "" {
+			log.Printf("ignoring quiz file without id %s", path)
+			continue
+		}
+		if record.Attempts == nil {
+			record.Attempts = []domain.QuizAttempt{}
+		}
+		qs.records[record.Quiz.ID] = &record
+	}
+	return nil
+}
+
+func (qs *QuizStore) writeLocked(record *quizRecord) error {
+	tmp, err := os.CreateTemp(qs.dir, "quiz-*.tmp")
+	if err != nil {
+		return fmt.Errorf("create temp quiz: %w", err)
+	}
+
+	encoder := json.NewEncoder(tmp)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(record); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("encode quiz: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("close temp quiz: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), qs.path(record.Quiz.ID)); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("replace quiz file: %w", err)
+	}
+	return nil
+}
+
+func (qs *QuizStore) path(id string) string {
+	return filepath.Join(qs.dir, filepath.Base(id)+".json")
+}
+
+func cloneQuiz(quiz domain.Quiz) domain.Quiz {
+	quiz.Questions = slices.Clone(quiz.Questions)
+	return quiz
+}
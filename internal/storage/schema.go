@@ -0,0 +1,66 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"myProfessor/internal/domain"
+)
+
+type metaMigration struct {
+	version int
+	name    string
+	apply   func(data *metaData) error
+}
+
+var metaMigrations = []metaMigration{
+	{version: 1, name: "backfill processing status", apply: backfillProcessingStatus},
+}
+
+func currentSchemaVersion() int {
+	return metaMigrations[len(metaMigrations)-1].version
+}
+
+func migrateMeta(data *metaData) ([]string, error) {
+	current := currentSchemaVersion()
+	if data.SchemaVersion > current {
+		return nil, fmt.Errorf("meta schema version %d is newer than supported version %d", data.SchemaVersion, current)
+	}
+
+	applied := make([]string, 0)
+	for _, migration := range metaMigrations {
+		if migration.version <= data.SchemaVersion {
+			continue
+		}
+		if err := migration.apply(data); err != nil {
+			return nil, fmt.Errorf("meta migration %d (%s): %w", migration.version, migration.name, err)
+		}
+		data.SchemaVersion = migration.version
+		applied = append(applied, migration.name)
+	}
+	return applied, nil
+}
+
+func backupMetaFile(path string, raw []byte, version int) (string, error) {
+	backup := fmt.Sprintf("%s.v%d-%d.bak", path, version, time.Now().Unix())
+	if err := os.WriteFile(backup, raw, 0o644); err != nil {
+		return "", fmt.Errorf("backup meta file: %w", err)
+	}
+	return backup, nil
+}
+
+func backfillProcessingStatus(data *metaData) error {
+	for id, doc := range data.Documents {
+		if doc.ProcessingStatus != "" {
+			continue
+		}
+		doc.ProcessingStatus = domain.ProcessingStatusPending
+		if strings.TrimSpace(doc.Transcription) != "" {
+			doc.ProcessingStatus = domain.ProcessingStatusCompleted
+		}
+		data.Documents[id] = doc
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package storage
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"myProfessor/internal/domain"
+)
+
+func TestMetaMigrationsAreOrdered(t *testing.T) {
+	for i, migration := range metaMigrations {
+		if migration.version != i+1 {
+			t.Fatalf("migration %q has version %d, expected %d", migration.name, migration.version, i+1)
+		}
+	}
+}
+
+func TestLoadUpgradesLegacyMetaFile(t *testing.T) {
+	dir := t.TempDir()
+	legacy := `{"folders":{},"documents":{"d1":{"id":"d1","title":"Cours","transcription":"texte"},"d2":{"id":"d2","title":"Vide"}}}`
+	if err := os.WriteFile(filepath.Join(dir, "meta.json"), []byte(legacy), 0o644); err != nil {
+		t.Fatalf("write meta: %v", err)
+	}
+
+	store, err := NewJSONStore(dir)
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+
+	withText, _ := store.GetDocument("d1")
+	empty, _ := store.GetDocument("d2")
+	if withText.ProcessingStatus != domain.ProcessingStatusCompleted || empty.ProcessingStatus != domain.ProcessingStatusPending {
+		t.Fatalf("unexpected backfilled statuses %q and %q", withText.ProcessingStatus, empty.ProcessingStatus)
+	}
+
+	backups, _ := filepath.Glob(filepath.Join(dir, "meta.json.v0-*.bak"))
+	if len(backups) != 1 {
+		t.Fatalf("expected one backup file, got %v", backups)
+	}
+	saved, _ := os.ReadFile(backups[0])
+	if string(saved) != legacy {
+		t.Fatalf("backup does not match the original file")
+	}
+
+	raw, err := os.ReadFile(filepath.Join(dir, "meta.json"))
+	if err != nil {
+		t.Fatalf("read meta: %v", err)
+	}
+	var upgraded metaData
+	if err := json.Unmarshal(raw, &upgraded); err != nil {
+		t.Fatalf("decode meta: %v", err)
+	}
+	if upgraded.SchemaVersion != currentSchemaVersion() {
+		t.Fatalf("expected schema version %d, got %d", currentSchemaVersion(), upgraded.SchemaVersion)
+	}
+
+	if _, err := NewJSONStore(dir); err != nil {
+		t.Fatalf("reopen store: %v", err)
+	}
+	if backups, _ := filepath.Glob(filepath.Join(dir, "*.bak")); len(backups) != 1 {
+		t.Fatalf("expected no new backup for an up-to-date file, got %v", backups)
+	}
+}
+
+func TestLoadRejectsNewerSchema(t *testing.T) {
+	dir := t.TempDir()
+	future := `{"schemaVersion":999,"folders":{},"documents":{}}`
+	if err := os.WriteFile(filepath.Join(dir, "meta.json"), []byte(future), 0o644); err != nil {
+		t.Fatalf("write meta: %v", err)
+	}
+
+	if _, err := NewJSONStore(dir); err == nil || !strings.Contains(err.Error(), "newer than supported") {
+		t.Fatalf("expected newer schema error, got %v", err)
+	}
+}
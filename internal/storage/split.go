@@ -0,0 +1,224 @@
+package storage
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	chunkTargetSeconds   = 20 * 60
+	chunkOverlapSeconds  = 5
+	silenceSearchSeconds = 60
+	silenceNoiseLevel    = "-35dB"
+	silenceMinDuration   = "0.6"
+	chunkBitrate         = "64k"
+	chunkSampleRate      = "22050"
+	chunkSuffix          = "_part"
+)
+
+var (
+	silenceStartPattern = regexp.MustCompile(`silence_start: (-?[0-9.]+)`)
+	silenceEndPattern   = regexp.MustCompile(`silence_end: (-?[0-9.]+)`)
+)
+
+type AudioChunk struct {
+	Path  string
+	Start float64
+	End   float64
+}
+
+type silenceRange struct {
+	start float64
+	end   float64
+}
+
+func (fm *FileManager) SplitAudio(inputPath string) ([]AudioChunk, error) {
+	if inputPath == "" {
+		return nil, fmt.Errorf("no audio path provided for splitting")
+	}
+
+	if _, err := exec.LookPath(ffmpegBinary); err != nil {
+		return nil, fmt.Errorf("ffmpeg not found in PATH: %w", err)
+	}
+
+	duration, err := probeDuration(inputPath)
+	if err != nil {
+		return nil, err
+	}
+
+	silences, err := detectSilences(inputPath)
+	if err != nil {
+		fmt.Printf("warning: silence detection failed, splitting on fixed boundaries: %v\n", err)
+		silences = nil
+	}
+
+	cuts := chunkBoundaries(duration, silences)
+	base := strings.TrimSuffix(filepath.Base(inputPath), filepath.Ext(inputPath))
+
+	chunks := make([]AudioChunk, 0, len(cuts)-1)
+	for i := 0; i < len(cuts)-1; i++ {
+		start := cuts[i]
+		if i > 0 {
+			start -= chunkOverlapSeconds
+			if start < 0 {
+				start = 0
+			}
+		}
+		end := cuts[i+1]
+
+		output := filepath.Join(fm.audioDir, fmt.Sprintf("%s%s%03d%s", base, chunkSuffix, i+1, compressedExt))
+		args := []string{
+			"-y",
+			"-ss", formatSeconds(start),
+			"-t", formatSeconds(end - start),
+			"-i", inputPath,
+			"-vn",
+			"-ac", "1",
+			"-acodec", "libmp3lame",
+			"-b:a", chunkBitrate,
+			"-ar", chunkSampleRate,
+			output,
+		}
+
+		cmd := exec.Command(ffmpegBinary, args...)
+		var stderr bytes.Buffer
+		cmd.Stderr = &stderr
+		if err := cmd.Run(); err != nil {
+			fm.RemoveChunks(chunks)
+			return nil, fmt.Errorf("split audio chunk %d: %w: %s", i+1, err, strings.TrimSpace(stderr.String()))
+		}
+
+		chunk := AudioChunk{Path: output, Start: start, End: end}
+		chunks = append(chunks, chunk)
+
+		if err := fm.ensureWithinWhisperLimit(output); err != nil {
+			fm.RemoveChunks(chunks)
+			return nil, fmt.Errorf("audio chunk %d: %w", i+1, err)
+		}
+	}
+
+	return chunks, nil
+}
+
+func (fm *FileManager) RemoveChunks(chunks []AudioChunk) {
+	for _, chunk := range chunks {
+		_ = os.Remove(chunk.Path)
+	}
+}
+
+func chunkBoundaries(duration float64, silences []silenceRange) []float64 {
+	cuts := []float64{0}
+	pos := 0.0
+
+	for duration-pos > chunkTargetSeconds {
+		target := pos + chunkTargetSeconds
+		cut := target
+
+		best := -1.0
+		for _, silence := range silences {
+			mid := (silence.start + silence.end) / 2
+			if mid <= pos || mid > target || target-mid > silenceSearchSeconds {
+				continue
+			}
+			if mid > best {
+				best = mid
+			}
+		}
+		if best > 0 {
+			cut = best
+		}
+
+		cuts = append(cuts, cut)
+		pos = cut
+	}
+
+	return append(cuts, duration)
+}
+
+func probeDuration(path string) (float64, error) {
+	if _, err := exec.LookPath(ffprobeBinary); err != nil {
+		return 0, fmt.Errorf("ffprobe not found in PATH: %w", err)
+	}
+
+	cmd := exec.Command(ffprobeBinary,
+		"-v", "error",
+		"-show_entries", "format=duration",
+		"-of", "default=noprint_wrappers=1:nokey=1",
+		path,
+	)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return 0, fmt.Errorf("probe audio duration: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	duration, err := strconv.ParseFloat(strings.TrimSpace(stdout.String()), 64)
+	if err != nil {
+		return 0, fmt.Errorf("parse audio duration: %w", err)
+	}
+	return duration, nil
+}
+
+func detectSilences(path string) ([]silenceRange, error) {
+	cmd := exec.Command(ffmpegBinary,
+		"-hide_banner",
+		"-nostats",
+		"-i", path,
+		"-af", fmt.Sprintf("silencedetect=noise=%s:d=%s", silenceNoiseLevel, silenceMinDuration),
+		"-f", "null",
+		"-",
+	)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("detect silences: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	return parseSilences(stderr.String()), nil
+}
+
+func parseSilences(output string) []silenceRange {
+	silences := make([]silenceRange, 0)
+	var current *silenceRange
+
+	for _, line := range strings.Split(output, "\n") {
+		if match := silenceStartPattern.FindStringSubmatch(line); match != nil {
+			start, err := strconv.ParseFloat(match[1], 64)
+			if err != nil {
+				continue
+			}
+			current = &silenceRange{start: start}
+			continue
+		}
+		if match := silenceEndPattern.FindStringSubmatch(line); match != nil && current != nil {
+			end, err := strconv.ParseFloat(match[1], 64)
+			if err != nil {
+				continue
+			}
+			current.end = end
+			silences = append(silences, *current)
+			current = nil
+		}
+	}
+
+	return silences
+}
+
+func estimateEncodedBytes(durationSeconds float64, bitrate string) int64 {
+	kbps, err := strconv.ParseFloat(strings.TrimSuffix(bitrate, "k"), 64)
+	if err != nil {
+		return 0
+	}
+	return int64(durationSeconds * kbps * 1000 / 8)
+}
+
+func formatSeconds(seconds float64) string {
+	return strconv.FormatFloat(seconds, 'f', 3, 64)
+}
@@ -0,0 +1,114 @@
+package storage
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestChunkBoundaries(t *testing.T) {
+	tests := []struct {
+		name     string
+		duration float64
+		silences []silenceRange
+		want     []float64
+	}{
+		{
+			name:     "under the limit",
+			duration: 600,
+			want:     []float64{0, 600},
+		},
+		{
+			name:     "exactly the limit",
+			duration: chunkTargetSeconds,
+			want:     []float64{0, chunkTargetSeconds},
+		},
+		{
+			name:     "exact multiple without silences",
+			duration: 2 * chunkTargetSeconds,
+			want:     []float64{0, chunkTargetSeconds, 2 * chunkTargetSeconds},
+		},
+		{
+			name:     "no silences",
+			duration: 2500,
+			want:     []float64{0, 1200, 2400, 2500},
+		},
+		{
+			name:     "silence inside the search window",
+			duration: 2500,
+			silences: []silenceRange{{start: 1170, end: 1172}},
+			want:     []float64{0, 1171, 2371, 2500},
+		},
+		{
+			name:     "latest silence in the window wins",
+			duration: 1500,
+			silences: []silenceRange{{start: 1150, end: 1152}, {start: 1180, end: 1182}},
+			want:     []float64{0, 1181, 1500},
+		},
+		{
+			name:     "silences outside the search window",
+			duration: 1500,
+			silences: []silenceRange{{start: 1100, end: 1102}, {start: 1250, end: 1252}},
+			want:     []float64{0, 1200, 1500},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := chunkBoundaries(tt.duration, tt.silences); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("chunkBoundaries(%v) = %v, want %v", tt.duration, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseSilences(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   []silenceRange
+	}{
+		{
+			name:   "empty output",
+			output: "",
+			want:   []silenceRange{},
+		},
+		{
+			name:   "no silences",
+			output: "Input #0, mp3, from 'lecture.mp3':\n  Duration: 00:40:00.00, start: 0.000000, bitrate: 64 kb/s\n",
+			want:   []silenceRange{},
+		},
+		{
+			name: "paired ranges",
+			output: "[silencedetect @ 0x5581] silence_start: -0.0213\n" +
+				"[silencedetect @ 0x5581] silence_end: 1.25 | silence_duration: 1.2713\n" +
+				"[silencedetect @ 0x5581] silence_start: 1180.4\n" +
+				"[silencedetect @ 0x5581] silence_end: 1181.6 | silence_duration: 1.2\n",
+			want: []silenceRange{{start: -0.0213, end: 1.25}, {start: 1180.4, end: 1181.6}},
+		},
+		{
+			name: "end without start and trailing start",
+			output: "[silencedetect @ 0x5581] silence_end: 3.5 | silence_duration: 1\n" +
+				"[silencedetect @ 0x5581] silence_start: 10\n" +
+				"[silencedetect @ 0x5581] silence_end: 11 | silence_duration: 1\n" +
+				"[silencedetect @ 0x5581] silence_start: 2400\n",
+			want: []silenceRange{{start: 10, end: 11}},
+		},
+		{
+			name: "malformed numbers",
+			output: "[silencedetect @ 0x5581] silence_start: 1.2.3\n" +
+				"[silencedetect @ 0x5581] silence_end: 4 | silence_duration: 1\n" +
+				"[silencedetect @ 0x5581] silence_start: 20\n" +
+				"[silencedetect @ 0x5581] silence_end: .. | silence_duration: 1\n" +
+				"[silencedetect @ 0x5581] silence_end: 21.5 | silence_duration: 1.5\n",
+			want: []silenceRange{{start: 20, end: 21.5}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseSilences(tt.output); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("parseSilences() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
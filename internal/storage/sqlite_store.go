@@ -0,0 +1,543 @@
+package storage
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	_ "modernc.org/sqlite"
+
+	"myProfessor/internal/domain"
+)
+
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS folders (
+	id         TEXT PRIMARY KEY,
+	name       TEXT NOT NULL,
+	created_at INTEGER NOT NULL,
+	updated_at INTEGER NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS documents (
+	id                TEXT PRIMARY KEY,
+	folder_id         TEXT NOT NULL DEFAULT '',
+	processing_status TEXT NOT NULL DEFAULT '',
+	created_at        INTEGER NOT NULL,
+	updated_at        INTEGER NOT NULL,
+	data              TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS documents_folder_id ON documents (folder_id, created_at);
+CREATE INDEX IF NOT EXISTS documents_processing_status ON documents (processing_status);
+`
+
+type sqliteMigration struct {
+	version int
+	name    string
+	sql     string
+}
+
+var sqliteMigrations = []sqliteMigration{
+	{version: 1, name: "add folder pdf template", sql: `ALTER TABLE folders ADD COLUMN pdf_template TEXT NOT NULL DEFAULT ''`},
+}
+
+type SQLiteStore struct {
+	mu      sync.Mutex
+	db      *sql.DB
+	changes changeNotifier
+}
+
+func NewSQLiteStore(path string) (*SQLiteStore, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("create data directory: %w", err)
+	}
+
+	dsn := "file:" + path + "?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)&_pragma=synchronous(NORMAL)"
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("open sqlite database: %w", err)
+	}
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("create sqlite schema: %w", err)
+	}
+	if err := migrateSQLite(db); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &SQLiteStore{db: db}, nil
+}
+
+func (s *SQLiteStore) Close() error {
+	return s.db.Close()
+}
+
+func (s *SQLiteStore) OnDocumentChange(fn func(doc domain.Document, deleted bool)) {
+	s.changes.set(fn)
+}
+
+func (s *SQLiteStore) CreateFolder(name string) (domain.Folder, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now().Unix()
+	folder := domain.Folder{
+		ID:          uuid.NewString(),
+		Name:        name,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+		DocumentIDs: []string{},
+	}
+
+	if _, err := s.db.Exec(
+		`INSERT INTO folders (id, name, created_at, updated_at) VALUES (?, ?, ?, ?)`,
+		folder.ID, folder.Name, folder.CreatedAt, folder.UpdatedAt,
+	); err != nil {
+		return domain.Folder{}, fmt.Errorf("insert folder: %w", err)
+	}
+
+	return folder, nil
+}
+
+func (s *SQLiteStore) ListFolders() []domain.Folder {
+	rows, err := s.db.Query(`SELECT id, name, created_at, updated_at, pdf_template FROM folders`)
+	if err != nil {
+		log.Printf("list folders: %v", err)
+		return []domain.Folder{}
+	}
+	defer rows.Close()
+
+	folders := make([]domain.Folder, 0)
+	index := map[string]int{}
+	for rows.Next() {
+		folder := domain.Folder{DocumentIDs: []string{}}
+		if err := rows.Scan(&folder.ID, &folder.Name, &folder.CreatedAt, &folder.UpdatedAt, &folder.PDFTemplate); err != nil {
+			log.Printf("scan folder: %v", err)
+			return []domain.Folder{}
+		}
+		index[folder.ID] = len(folders)
+		folders = append(folders, folder)
+	}
+	if err := rows.Err(); err != nil {
+		log.Printf("list folders: %v", err)
+		return []domain.Folder{}
+	}
+
+	docRows, err := s.db.Query(`SELECT id, folder_id FROM documents WHERE folder_id != '' ORDER BY created_at, rowid`)
+	if err != nil {
+		log.Printf("list folder documents: %v", err)
+		return folders
+	}
+	defer docRows.Close()
+
+	for docRows.Next() {
+		var docID, folderID string
+		if err := docRows.Scan(&docID, &folderID); err != nil {
+			log.Printf("scan folder document: %v", err)
+			return folders
+		}
+		if i, ok := index[folderID]; ok {
+			folders[i].DocumentIDs = append(folders[i].DocumentIDs, docID)
+		}
+	}
+
+	return folders
+}
+
+func (s *SQLiteStore) GetFolder(id string) (domain.Folder, error) {
+	folder := domain.Folder{DocumentIDs: []string{}}
+	err := s.db.QueryRow(`SELECT id, name, created_at, updated_at, pdf_template FROM folders WHERE id = ?`, id).
+		Scan(&folder.ID, &folder.Name, &folder.CreatedAt, &folder.UpdatedAt, &folder.PDFTemplate)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.Folder{}, fmt.Errorf("folder %s not found", id)
+	}
+	if err != nil {
+		return domain.Folder{}, fmt.Errorf("get folder: %w", err)
+	}
+
+	rows, err := s.db.Query(`SELECT id FROM documents WHERE folder_id = ? ORDER BY created_at, rowid`, id)
+	if err != nil {
+		return domain.Folder{}, fmt.Errorf("list folder documents: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var docID string
+		if err := rows.Scan(&docID); err != nil {
+			return domain.Folder{}, fmt.Errorf("scan folder document: %w", err)
+		}
+		folder.DocumentIDs = append(folder.DocumentIDs, docID)
+	}
+	if err := rows.Err(); err != nil {
+		return domain.Folder{}, fmt.Errorf("list folder documents: %w", err)
+	}
+
+	return folder, nil
+}
+
+func (s *SQLiteStore) RenameFolder(id, newName string) (domain.Folder, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result, err := s.db.Exec(`UPDATE folders SET name = ?, updated_at = ? WHERE id = ?`, newName, time.Now().Unix(), id)
+	if err != nil {
+		return domain.Folder{}, fmt.Errorf("rename folder: %w", err)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return domain.Folder{}, fmt.Errorf("folder %s not found", id)
+	}
+
+	return s.GetFolder(id)
+}
+
+func (s *SQLiteStore) SetFolderPDFTemplate(id, template string) (domain.Folder, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result, err := s.db.Exec(`UPDATE folders SET pdf_template = ?, updated_at = ? WHERE id = ?`, template, time.Now().Unix(), id)
+	if err != nil {
+		return domain.Folder{}, fmt.Errorf("set folder pdf template: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return domain.Folder{}, fmt.Errorf("folder %s not found", id)
+	}
+	return s.GetFolder(id)
+}
+
+func (s *SQLiteStore) DeleteFolder(id string) error {
+	defer s.changes.flush()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`DELETE FROM folders WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("delete folder: %w", err)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return fmt.Errorf("folder %s not found", id)
+	}
+
+	deleted, err := queryDocuments(tx, `SELECT data FROM documents WHERE folder_id = ?`, id)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM documents WHERE folder_id = ?`, id); err != nil {
+		return fmt.Errorf("delete folder documents: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+	for _, doc := range deleted {
+		s.changes.enqueue(doc, true)
+	}
+	return nil
+}
+
+func (s *SQLiteStore) CreateDocument(doc domain.Document) (domain.Document, error) {
+	defer s.changes.flush()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if doc.ID == "" {
+		doc.ID = uuid.NewString()
+	}
+	if doc.ProcessingStatus == "" {
+		doc.ProcessingStatus = domain.ProcessingStatusPending
+	}
+	now := time.Now().Unix()
+	if doc.CreatedAt == 0 {
+		doc.CreatedAt = now
+	}
+	doc.UpdatedAt = now
+
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return domain.Document{}, fmt.Errorf("encode document: %w", err)
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return domain.Document{}, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(
+		`INSERT INTO documents (id, folder_id, processing_status, created_at, updated_at, data) VALUES (?, ?, ?, ?, ?, ?)`,
+		doc.ID, doc.FolderID, doc.ProcessingStatus, doc.CreatedAt, doc.UpdatedAt, string(data),
+	); err != nil {
+		return domain.Document{}, fmt.Errorf("insert document: %w", err)
+	}
+	if err := touchFolder(tx, doc.FolderID, now); err != nil {
+		return domain.Document{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return domain.Document{}, fmt.Errorf("commit transaction: %w", err)
+	}
+	s.changes.enqueue(doc, false)
+
+	return doc, nil
+}
+
+func (s *SQLiteStore) ListDocumentsByFolder(folderID string) []domain.Document {
+	docs, err := queryDocuments(s.db, `SELECT data FROM documents WHERE folder_id = ? ORDER BY created_at, rowid`, folderID)
+	if err != nil {
+		log.Printf("list documents of folder %s: %v", folderID, err)
+		return []domain.Document{}
+	}
+	return docs
+}
+
+func (s *SQLiteStore) ListDocuments() []domain.Document {
+	docs, err := queryDocuments(s.db, `SELECT data FROM documents ORDER BY created_at, rowid`)
+	if err != nil {
+		log.Printf("list documents: %v", err)
+		return []domain.Document{}
+	}
+	return docs
+}
+
+func (s *SQLiteStore) GetDocument(id string) (domain.Document, error) {
+	return getDocument(s.db, id)
+}
+
+func (s *SQLiteStore) UpdateDocument(doc domain.Document) (domain.Document, error) {
+	defer s.changes.flush()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return domain.Document{}, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	existing, err := getDocument(tx, doc.ID)
+	if err != nil {
+		return domain.Document{}, err
+	}
+
+	if doc.CreatedAt == 0 {
+		doc.CreatedAt = existing.CreatedAt
+	}
+	if doc.ProcessingStatus == "" {
+		doc.ProcessingStatus = existing.ProcessingStatus
+	}
+	now := time.Now().Unix()
+	doc.UpdatedAt = now
+
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return domain.Document{}, fmt.Errorf("encode document: %w", err)
+	}
+
+	if _, err := tx.Exec(
+		`UPDATE documents SET folder_id = ?, processing_status = ?, created_at = ?, updated_at = ?, data = ? WHERE id = ?`,
+		doc.FolderID, doc.ProcessingStatus, doc.CreatedAt, doc.UpdatedAt, string(data), doc.ID,
+	); err != nil {
+		return domain.Document{}, fmt.Errorf("update document: %w", err)
+	}
+
+	if doc.FolderID != existing.FolderID {
+		if err := touchFolder(tx, existing.FolderID, now); err != nil {
+			return domain.Document{}, err
+		}
+		if err := touchFolder(tx, doc.FolderID, now); err != nil {
+			return domain.Document{}, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return domain.Document{}, fmt.Errorf("commit transaction: %w", err)
+	}
+	s.changes.enqueue(doc, false)
+	return doc, nil
+}
+
+func (s *SQLiteStore) DeleteDocument(id string) error {
+	defer s.changes.flush()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	doc, err := getDocument(tx, id)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`DELETE FROM documents WHERE id = ?`, id); err != nil {
+		return fmt.Errorf("delete document: %w", err)
+	}
+	if err := touchFolder(tx, doc.FolderID, time.Now().Unix()); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+	s.changes.enqueue(doc, true)
+	return nil
+}
+
+type sqlQueryer interface {
+	Query(query string, args ...any) (*sql.Rows, error)
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+func getDocument(q sqlQueryer, id string) (domain.Document, error) {
+	var data string
+	err := q.QueryRow(`SELECT data FROM documents WHERE id = ?`, id).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.Document{}, fmt.Errorf("document %s not found", id)
+	}
+	if err != nil {
+		return domain.Document{}, fmt.Errorf("get document: %w", err)
+	}
+
+	var doc domain.Document
+	if err := json.Unmarshal([]byte(data), &doc); err != nil {
+		return domain.Document{}, fmt.Errorf("decode document %s: %w", id, err)
+	}
+	return doc, nil
+}
+
+func queryDocuments(q sqlQueryer, query string, args ...any) ([]domain.Document, error) {
+	rows, err := q.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query documents: %w", err)
+	}
+	defer rows.Close()
+
+	docs := make([]domain.Document, 0)
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, fmt.Errorf("scan document: %w", err)
+		}
+		var doc domain.Document
+		if err := json.Unmarshal([]byte(data), &doc); err != nil {
+			return nil, fmt.Errorf("decode document: %w", err)
+		}
+		docs = append(docs, doc)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("query documents: %w", err)
+	}
+	return docs, nil
+}
+
+func migrateSQLite(db *sql.DB) error {
+	var version int
+	if err := db.QueryRow(`PRAGMA user_version`).Scan(&version); err != nil {
+		return fmt.Errorf("read sqlite schema version: %w", err)
+	}
+	current := sqliteMigrations[len(sqliteMigrations)-1].version
+	if version > current {
+		return fmt.Errorf("sqlite schema version %d is newer than supported version %d", version, current)
+	}
+
+	for _, migration := range sqliteMigrations {
+		if migration.version <= version {
+			continue
+		}
+		if err := applySQLiteMigration(db, migration); err != nil {
+			return fmt.Errorf("sqlite migration %d (%s): %w", migration.version, migration.name, err)
+		}
+		log.Printf("sqlite schema migrated to version %d (%s)", migration.version, migration.name)
+	}
+	return nil
+}
+
+func applySQLiteMigration(db *sql.DB, migration sqliteMigration) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(migration.sql); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(fmt.Sprintf(`PRAGMA user_version = %d`, migration.version)); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func touchFolder(tx *sql.Tx, folderID string, now int64) error {
+	if folderID == "" {
+		return nil
+	}
+	if _, err := tx.Exec(`UPDATE folders SET updated_at = ? WHERE id = ?`, now, folderID); err != nil {
+		return fmt.Errorf("touch folder: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStore) Import(snapshot Snapshot) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var existing int
+	if err := tx.QueryRow(`SELECT (SELECT COUNT(*) FROM folders) + (SELECT COUNT(*) FROM documents)`).Scan(&existing); err != nil {
+		return fmt.Errorf("count existing rows: %w", err)
+	}
+	if existing > 0 {
+		return errors.New("target database is not empty")
+	}
+
+	for _, folder := range snapshot.Folders {
+		if _, err := tx.Exec(
+			`INSERT INTO folders (id, name, created_at, updated_at, pdf_template) VALUES (?, ?, ?, ?, ?)`,
+			folder.ID, folder.Name, folder.CreatedAt, folder.UpdatedAt, folder.PDFTemplate,
+		); err != nil {
+			return fmt.Errorf("insert folder %s: %w", folder.ID, err)
+		}
+	}
+
+	for _, doc := range snapshot.Documents {
+		data, err := json.Marshal(doc)
+		if err != nil {
+			return fmt.Errorf("encode document %s: %w", doc.ID, err)
+		}
+		if _, err := tx.Exec(
+			`INSERT INTO documents (id, folder_id, processing_status, created_at, updated_at, data) VALUES (?, ?, ?, ?, ?, ?)`,
+			doc.ID, doc.FolderID, doc.ProcessingStatus, doc.CreatedAt, doc.UpdatedAt, string(data),
+		); err != nil {
+			return fmt.Errorf("insert document %s: %w", doc.ID, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+	return nil
+}
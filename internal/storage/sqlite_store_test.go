@@ -0,0 +1,163 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"myProfessor/internal/domain"
+)
+
+func TestSQLiteStoreFolderDocuments(t *testing.T) {
+	store, err := NewSQLiteStore(filepath.Join(t.TempDir(), "meta.db"))
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	defer store.Close()
+
+	var deleted []string
+	store.OnDocumentChange(func(doc domain.Document, isDeleted bool) {
+		if isDeleted {
+			deleted = append(deleted, doc.ID)
+		}
+	})
+
+	math, err := store.CreateFolder("Maths")
+	if err != nil {
+		t.Fatalf("create folder: %v", err)
+	}
+	physics, err := store.CreateFolder("Physique")
+	if err != nil {
+		t.Fatalf("create folder: %v", err)
+	}
+
+	first, err := store.CreateDocument(domain.Document{FolderID: math.ID, Title: "Intégrales", CreatedAt: 1})
+	if err != nil {
+		t.Fatalf("create document: %v", err)
+	}
+	second, err := store.CreateDocument(domain.Document{
+		FolderID:  math.ID,
+		Title:     "Dérivées",
+		CreatedAt: 2,
+		Pipeline:  &domain.Pipeline{Steps: []string{domain.PipelineStepTranscribe}, Completed: []string{}},
+	})
+	if err != nil {
+		t.Fatalf("create document: %v", err)
+	}
+	if first.ProcessingStatus != domain.ProcessingStatusPending {
+		t.Fatalf("expected pending status by default, got %q", first.ProcessingStatus)
+	}
+
+	folder, err := store.GetFolder(math.ID)
+	if err != nil {
+		t.Fatalf("get folder: %v", err)
+	}
+	if len(folder.DocumentIDs) != 2 || folder.DocumentIDs[0] != first.ID || folder.DocumentIDs[1] != second.ID {
+		t.Fatalf("unexpected folder documents %v", folder.DocumentIDs)
+	}
+
+	second.FolderID = physics.ID
+	second.ProcessingStatus = ""
+	if _, err := store.UpdateDocument(second); err != nil {
+		t.Fatalf("update document: %v", err)
+	}
+
+	moved, err := store.GetDocument(second.ID)
+	if err != nil {
+		t.Fatalf("get document: %v", err)
+	}
+	if moved.CreatedAt != 2 || moved.ProcessingStatus != domain.ProcessingStatusPending || moved.Pipeline == nil {
+		t.Fatalf("document fields not preserved: %+v", moved)
+	}
+	if docs := store.ListDocumentsByFolder(physics.ID); len(docs) != 1 || docs[0].ID != second.ID {
+		t.Fatalf("expected moved document in physics folder, got %+v", docs)
+	}
+
+	if err := store.DeleteFolder(math.ID); err != nil {
+		t.Fatalf("delete folder: %v", err)
+	}
+	if _, err := store.GetDocument(first.ID); err == nil {
+		t.Fatalf("expected document of deleted folder to be removed")
+	}
+	if len(deleted) != 1 || deleted[0] != first.ID {
+		t.Fatalf("expected deletion notification for %s, got %v", first.ID, deleted)
+	}
+	if folders := store.ListFolders(); len(folders) != 1 || folders[0].ID != physics.ID || len(folders[0].DocumentIDs) != 1 {
+		t.Fatalf("unexpected folders after delete: %+v", folders)
+	}
+
+	if _, err := store.UpdateDocument(domain.Document{ID: "missing"}); err == nil {
+		t.Fatalf("expected error when updating a missing document")
+	}
+}
+
+func TestSQLiteMigrationsAreOrdered(t *testing.T) {
+	for i, migration := range sqliteMigrations {
+		if migration.version != i+1 {
+			t.Fatalf("migration %q has version %d, expected %d", migration.name, migration.version, i+1)
+		}
+	}
+}
+
+func TestSQLiteStoreMigratesLegacySchema(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "meta.db")
+	legacy, err := sql.Open("sqlite", "file:"+path)
+	if err != nil {
+		t.Fatalf("open legacy database: %v", err)
+	}
+	if _, err := legacy.Exec(`CREATE TABLE folders (id TEXT PRIMARY KEY, name TEXT NOT NULL, created_at INTEGER NOT NULL, updated_at INTEGER NOT NULL);
+		INSERT INTO folders VALUES ('f1', 'Maths', 1, 1);`); err != nil {
+		t.Fatalf("create legacy schema: %v", err)
+	}
+	legacy.Close()
+
+	store, err := NewSQLiteStore(path)
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	defer store.Close()
+
+	folder, err := store.SetFolderPDFTemplate("f1", "examen")
+	if err != nil {
+		t.Fatalf("set folder template: %v", err)
+	}
+	if folder.PDFTemplate != "examen" || folder.Name != "Maths" {
+		t.Fatalf("unexpected folder %+v", folder)
+	}
+	if _, err := store.SetFolderPDFTemplate("missing", "examen"); err == nil {
+		t.Fatal("expected an error for an unknown folder")
+	}
+
+	var version int
+	if err := store.db.QueryRow(`PRAGMA user_version`).Scan(&version); err != nil {
+		t.Fatalf("read schema version: %v", err)
+	}
+	if want := sqliteMigrations[len(sqliteMigrations)-1].version; version != want {
+		t.Fatalf("expected schema version %d, got %d", want, version)
+	}
+	store.Close()
+
+	reopened, err := NewSQLiteStore(path)
+	if err != nil {
+		t.Fatalf("reopen migrated store: %v", err)
+	}
+	reopened.Close()
+}
+
+func TestSQLiteStoreRejectsNewerSchema(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "meta.db")
+	future, err := sql.Open("sqlite", "file:"+path)
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	if _, err := future.Exec(fmt.Sprintf(`PRAGMA user_version = %d`, len(sqliteMigrations)+1)); err != nil {
+		t.Fatalf("set schema version: %v", err)
+	}
+	future.Close()
+
+	if store, err := NewSQLiteStore(path); err == nil {
+		store.Close()
+		t.Fatal("expected an error for a schema newer than supported")
+	}
+}